@@ -0,0 +1,103 @@
+package efashevdsapigo
+
+import (
+	"fmt"
+	"math"
+)
+
+// Outcome of checking a requested amount against a vend validate response.
+type AmountCheck struct {
+	// The requested amount.
+	Amount float64
+	// Whether the product accepts the requested amount.
+	Accepted bool
+	// The nearest amount the product accepts, equals Amount when accepted.
+	Suggested float64
+	// Whether the available transaction balance covers the requested amount.
+	BalanceCovers bool
+	// The missing balance to vend the requested amount, zero when covered.
+	Shortfall float64
+}
+
+// AmountError is returned when a product does not accept a requested amount.
+// It wraps one of ErrAmountBelowMin, ErrAmountAboveMax or ErrAmountNotSelectable.
+type AmountError struct {
+	Err       error
+	Amount    float64
+	Suggested float64
+}
+
+func (e *AmountError) Error() string {
+	return fmt.Sprintf("%s: %v, nearest valid amount is %v", e.Err, e.Amount, e.Suggested)
+}
+
+func (e *AmountError) Unwrap() error {
+	return e.Err
+}
+
+// Check if the product accepts the amount and if the available transaction balance covers it.
+// The returned check is always set, the error is an *AmountError when the amount is not accepted,
+// or wraps ErrInsufficientBalance when only the balance does not cover it.
+func (r *VendValidateResp) CheckAmount(amount float64) (*AmountCheck, error) {
+
+	check := &AmountCheck{Amount: amount}
+	check.Suggested = r.NearestAmount(amount)
+	check.Accepted = check.Suggested == amount
+	check.BalanceCovers = r.Data.AvailTransactionBalance >= amount
+	if !check.BalanceCovers {
+		check.Shortfall = amount - r.Data.AvailTransactionBalance
+	}
+
+	if !check.Accepted {
+		aErr := &AmountError{Amount: amount, Suggested: check.Suggested}
+		switch {
+		case r.Data.VendUnitId == FixedVendUnitId && len(r.Data.SelectAmount) > 0:
+			aErr.Err = ErrAmountNotSelectable
+		case amount < r.Data.VendMin:
+			aErr.Err = ErrAmountBelowMin
+		default:
+			aErr.Err = ErrAmountAboveMax
+		}
+		return check, aErr
+	}
+	if !check.BalanceCovers {
+		return check, fmt.Errorf("%w: short of %v", ErrInsufficientBalance, check.Shortfall)
+	}
+	return check, nil
+}
+
+// The nearest amount accepted by the product.
+// For fixed products it is the closest denomination, the lowest one on ties.
+// For flexible products it is the amount clamped between VendMin and VendMax, a zero VendMax means no upper limit.
+func (r *VendValidateResp) NearestAmount(amount float64) float64 {
+
+	if r.Data.VendUnitId == FixedVendUnitId && len(r.Data.SelectAmount) > 0 {
+		nearest := r.Data.SelectAmount[0].Amount
+		for _, s := range r.Data.SelectAmount[1:] {
+			d, nd := math.Abs(s.Amount-amount), math.Abs(nearest-amount)
+			if d < nd || (d == nd && s.Amount < nearest) {
+				nearest = s.Amount
+			}
+		}
+		return nearest
+	}
+
+	if amount < r.Data.VendMin {
+		return r.Data.VendMin
+	}
+	if r.Data.VendMax > 0 && amount > r.Data.VendMax {
+		return r.Data.VendMax
+	}
+	return amount
+}
+
+// Amounts a customer can pick for the product.
+// For fixed products these are the denominations, for flexible ones the optional suggested amounts.
+func (r *VendValidateResp) Denominations() []float64 {
+
+	res := make([]float64, 0, len(r.Data.SelectAmount))
+	for _, s := range r.Data.SelectAmount {
+		res = append(res, s.Amount)
+	}
+	return res
+}
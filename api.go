@@ -9,8 +9,13 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type client struct {
@@ -20,11 +25,28 @@ type client struct {
 	refreshToken          string
 	accessTokenExpiresAt  time.Time
 	refreshTokenExpiresAt time.Time
-
-	baseURL         *url.URL
-	client          *http.Client
-	debugger        Debugger
-	autoUpdateToken bool
+	// Guards the tokens, authMu is held while renewing them so concurrent calls renew them once.
+	tokenMu sync.RWMutex
+	authMu  sync.Mutex
+
+	baseURL          *url.URL
+	client           *http.Client
+	debugger         Debugger
+	autoUpdateToken  bool
+	limiter          *RateLimiter
+	breaker          *CircuitBreaker
+	telemetry        *telemetry
+	logs             *logConfig
+	onRequest        []RequestHook
+	onResponse       []ResponseHook
+	idempotencyStore IdempotencyStore
+	inFlightTimeout  time.Duration
+	// Whether VendValidate checks account numbers with the registered validators.
+	validateAccountNumbers bool
+	journal                Journal
+
+	profileMu sync.RWMutex
+	profile   Profile
 }
 
 func NewClient(ctx context.Context, apiKey, apiSecret string, opts ...Option) (Client, error) {
@@ -41,8 +63,14 @@ func NewClient(ctx context.Context, apiKey, apiSecret string, opts ...Option) (C
 		apiSecret:       apiSecret,
 		autoUpdateToken: true,
 		client:          http.DefaultClient,
+		inFlightTimeout: DefaultInFlightTimeout,
 	}
 
+	var (
+		tp   trace.TracerProvider
+		mp   metric.MeterProvider
+		logs = logConfig{requestLevel: DefaultRequestLogLevel, failureLevel: DefaultFailureLogLevel}
+	)
 	for _, opt := range opts {
 		switch opt := opt.(type) {
 		case baseUrlOption:
@@ -53,8 +81,47 @@ func NewClient(ctx context.Context, apiKey, apiSecret string, opts ...Option) (C
 			c.client = opt.v
 		case debugOption:
 			c.debugger = opt.v
+		case idempotencyStoreOption:
+			c.idempotencyStore = opt.v
+		case accountNumberValidationOption:
+			c.validateAccountNumbers = bool(opt)
+		case inFlightTimeoutOption:
+			if opt > 0 {
+				c.inFlightTimeout = time.Duration(opt)
+			}
+		case journalOption:
+			c.journal = opt.v
+		case rateLimiterOption:
+			c.limiter = opt.v
+		case circuitBreakerOption:
+			c.breaker = opt.v
+		case tracerProviderOption:
+			tp = opt.v
+		case meterProviderOption:
+			mp = opt.v
+		case loggerOption:
+			logs.logger = opt.v
+		case logLevelsOption:
+			logs.requestLevel, logs.failureLevel = opt.request, opt.failure
+		case logBodiesOption:
+			logs.bodies = bool(opt)
+		case onRequestOption:
+			c.onRequest = append(c.onRequest, opt.v)
+		case onResponseOption:
+			c.onResponse = append(c.onResponse, opt.v)
 		}
 	}
+	if logs.logger != nil {
+		c.logs = &logs
+	}
+
+	if tp != nil || mp != nil {
+		t, err := newTelemetry(tp, mp)
+		if err != nil {
+			return nil, err
+		}
+		c.telemetry = t
+	}
 
 	if c.baseURL == nil {
 		u, err := url.Parse(APIV2BaseURL)
@@ -63,6 +130,9 @@ func NewClient(ctx context.Context, apiKey, apiSecret string, opts ...Option) (C
 		}
 		c.baseURL = u
 	}
+	if c.breaker != nil {
+		c.breaker.setProber(func(ctx context.Context) (*StatusResp, error) { return c.Status(ctx) })
+	}
 
 	err := c.InitAuth(ctx)
 	if err != nil {
@@ -74,21 +144,31 @@ func NewClient(ctx context.Context, apiKey, apiSecret string, opts ...Option) (C
 
 func (c *client) InitAuth(ctx context.Context, opts ...Option) error {
 
-	if c.accessTokenExpiresAt.After(time.Now()) {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	c.tokenMu.RLock()
+	accessExpiresAt, refreshExpiresAt := c.accessTokenExpiresAt, c.refreshTokenExpiresAt
+	c.tokenMu.RUnlock()
+	if accessExpiresAt.After(time.Now()) {
 		return nil
 	}
 	c.debug("[efashevdsapigo] access token expired.")
 
-	if c.refreshTokenExpiresAt.After(time.Now()) {
+	if refreshExpiresAt.After(time.Now()) {
 		res, err := c.RefreshToken(ctx, opts...)
 		if err != nil {
 			return err
 		}
-		c.accessToken = res.Data.AccessToken
-		c.accessTokenExpiresAt, err = parseTokenTstamp(res.Data.AccessToken)
+		t, err := parseTokenTstamp(res.Data.AccessToken)
 		if err != nil {
 			return err
 		}
+		c.tokenMu.Lock()
+		c.accessToken = res.Data.AccessToken
+		c.accessTokenExpiresAt = t
+		c.tokenMu.Unlock()
+		c.recordTokenRefresh(ctx, "refresh")
 		c.debug("[efashevdsapigo] access token renewed with refresh token.")
 		return nil
 	}
@@ -98,23 +178,30 @@ func (c *client) InitAuth(ctx context.Context, opts ...Option) error {
 	if err != nil {
 		return err
 	}
-	t, err := parseTokenTstamp(res.Data.AccessToken)
+	accessExpiresAt, err = parseTokenTstamp(res.Data.AccessToken)
 	if err != nil {
 		return err
 	}
-	c.accessToken = res.Data.AccessToken
-	c.accessTokenExpiresAt = t
-	t, err = parseTokenTstamp(res.Data.RefreshToken)
+	refreshExpiresAt, err = parseTokenTstamp(res.Data.RefreshToken)
 	if err != nil {
 		return err
 	}
+	c.tokenMu.Lock()
+	c.accessToken = res.Data.AccessToken
+	c.accessTokenExpiresAt = accessExpiresAt
 	c.refreshToken = res.Data.RefreshToken
-	c.refreshTokenExpiresAt = t
+	c.refreshTokenExpiresAt = refreshExpiresAt
+	c.tokenMu.Unlock()
+	c.setProfile(res)
+	c.recordTokenRefresh(ctx, "auth")
 	c.debug("[efashevdsapigo] fresh authentication was successful.")
 	return nil
 }
 
-func (c *client) Status(ctx context.Context, opts ...Option) (*StatusResp, error) {
+func (c *client) Status(ctx context.Context, opts ...Option) (out *StatusResp, err error) {
+
+	ctx, end := c.startCall(ctx, callInfo{endpoint: "/status"}, opts...)
+	defer func() { end(out, err) }()
 
 	cl, req, err := c.setRequestParams(ctx, nil, http.MethodGet, "/status", false, opts...)
 	if err != nil {
@@ -122,7 +209,7 @@ func (c *client) Status(ctx context.Context, opts ...Option) (*StatusResp, error
 	}
 
 	var res StatusResp
-	statusCode, status, err := httpDo(cl, req, &res, true)
+	statusCode, status, err := c.do(cl, req, &res)
 	if err != nil {
 		return nil, err
 	}
@@ -136,7 +223,10 @@ func (c *client) Status(ctx context.Context, opts ...Option) (*StatusResp, error
 	}
 }
 
-func (c *client) ValidateSession(ctx context.Context, opts ...Option) (bool, error) {
+func (c *client) ValidateSession(ctx context.Context, opts ...Option) (out bool, err error) {
+
+	ctx, end := c.startCall(ctx, callInfo{endpoint: "/validate/session"}, opts...)
+	defer func() { end(out, err) }()
 
 	cl, req, err := c.setRequestParams(ctx, nil, http.MethodGet, "/validate/session", true, opts...)
 	if err != nil {
@@ -146,7 +236,7 @@ func (c *client) ValidateSession(ctx context.Context, opts ...Option) (bool, err
 	var res struct {
 		Msg string `json:"msg"`
 	}
-	statusCode, status, err := httpDo(cl, req, &res, true)
+	statusCode, status, err := c.do(cl, req, &res)
 	if err != nil {
 		return false, err
 	}
@@ -162,7 +252,10 @@ func (c *client) ValidateSession(ctx context.Context, opts ...Option) (bool, err
 	}
 }
 
-func (c *client) Auth(ctx context.Context, opts ...Option) (*AuthResp, error) {
+func (c *client) Auth(ctx context.Context, opts ...Option) (out *AuthResp, err error) {
+
+	ctx, end := c.startCall(ctx, callInfo{endpoint: "/auth"}, opts...)
+	defer func() { end(out, err) }()
 
 	body := strings.NewReader(fmt.Sprintf(`{"api_key": %q, "api_secret": %q}`, c.apiKey, c.apiSecret))
 	cl, req, err := c.setRequestParams(ctx, body, http.MethodPost, "/auth", false, opts...)
@@ -174,7 +267,7 @@ func (c *client) Auth(ctx context.Context, opts ...Option) (*AuthResp, error) {
 		AuthResp
 		Msg string `json:"msg"`
 	}
-	statusCode, status, err := httpDo(cl, req, &res, true)
+	statusCode, status, err := c.do(cl, req, &res)
 	if err != nil {
 		return nil, err
 	}
@@ -199,9 +292,14 @@ func (c *client) Auth(ctx context.Context, opts ...Option) (*AuthResp, error) {
 	}
 }
 
-func (c *client) RefreshToken(ctx context.Context, opts ...Option) (*RefreshTokenResp, error) {
+func (c *client) RefreshToken(ctx context.Context, opts ...Option) (out *RefreshTokenResp, err error) {
 
+	ctx, end := c.startCall(ctx, callInfo{endpoint: "/refresh-token"}, opts...)
+	defer func() { end(out, err) }()
+
+	c.tokenMu.RLock()
 	body := strings.NewReader(fmt.Sprintf(`{"data": {"refreshToken": %q} }`, c.refreshToken))
+	c.tokenMu.RUnlock()
 	cl, req, err := c.setRequestParams(ctx, body, http.MethodPost, "/refresh-token", false, opts...)
 	if err != nil {
 		return nil, err
@@ -211,7 +309,7 @@ func (c *client) RefreshToken(ctx context.Context, opts ...Option) (*RefreshToke
 		RefreshTokenResp
 		Msg string `json:"msg"`
 	}
-	statusCode, status, err := httpDo(cl, req, &res, true)
+	statusCode, status, err := c.do(cl, req, &res)
 	if err != nil {
 		return nil, err
 	}
@@ -225,7 +323,10 @@ func (c *client) RefreshToken(ctx context.Context, opts ...Option) (*RefreshToke
 	}
 }
 
-func (c *client) Balance(ctx context.Context, opts ...Option) (*BalanceResp, error) {
+func (c *client) Balance(ctx context.Context, opts ...Option) (out *BalanceResp, err error) {
+
+	ctx, end := c.startCall(ctx, callInfo{endpoint: "/balance"}, opts...)
+	defer func() { end(out, err) }()
 
 	cl, req, err := c.setRequestParams(ctx, nil, http.MethodGet, "/balance", true, opts...)
 	if err != nil {
@@ -237,7 +338,7 @@ func (c *client) Balance(ctx context.Context, opts ...Option) (*BalanceResp, err
 		BalanceResp
 		Msg string `json:"msg"`
 	}
-	statusCode, status, err := httpDo(cl, req, &res, true)
+	statusCode, status, err := c.do(cl, req, &res)
 	if err != nil {
 		return nil, err
 	}
@@ -251,7 +352,10 @@ func (c *client) Balance(ctx context.Context, opts ...Option) (*BalanceResp, err
 	}
 }
 
-func (c *client) ListVerticals(ctx context.Context, opts ...Option) (*ListVerticalsResp, error) {
+func (c *client) ListVerticals(ctx context.Context, opts ...Option) (out *ListVerticalsResp, err error) {
+
+	ctx, end := c.startCall(ctx, callInfo{endpoint: "/verticals"}, opts...)
+	defer func() { end(out, err) }()
 
 	cl, req, err := c.setRequestParams(ctx, nil, http.MethodGet, "/verticals", true, opts...)
 	if err != nil {
@@ -262,7 +366,7 @@ func (c *client) ListVerticals(ctx context.Context, opts ...Option) (*ListVertic
 		ListVerticalsResp
 		Msg string `json:"msg"`
 	}
-	statusCode, status, err := httpDo(cl, req, &res, true)
+	statusCode, status, err := c.do(cl, req, &res)
 	if err != nil {
 		return nil, err
 	}
@@ -287,7 +391,35 @@ func (c *client) ListVerticals(ctx context.Context, opts ...Option) (*ListVertic
 	}
 }
 
-func (c *client) VendValidate(ctx context.Context, body VendValidateBody, opts ...Option) (*VendValidateResp, error) {
+// Whether account numbers are checked and normalized before vending, check WithAccountNumberValidationOption.
+func (c *client) validatesAccountNumbers(opts []Option) bool {
+
+	enabled := c.validateAccountNumbers
+	for _, opt := range opts {
+		if opt, ok := opt.(accountNumberValidationOption); ok {
+			enabled = bool(opt)
+		}
+	}
+	return enabled
+}
+
+func (c *client) VendValidate(ctx context.Context, body VendValidateBody, opts ...Option) (out *VendValidateResp, err error) {
+
+	ctx, end := c.startCall(ctx, callInfo{endpoint: "/vend/validate", verticalId: body.VerticalId}, opts...)
+	defer func() { end(out, err) }()
+
+	if c.validatesAccountNumbers(opts) {
+		body, err = body.Normalized()
+	} else {
+		err = body.validateRequired()
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = c.Profile().CanVend()
+	if err != nil {
+		return nil, err
+	}
 
 	bodyRaw, _ := json.Marshal(body)
 	cl, req, err := c.setRequestParams(ctx, bytes.NewReader(bodyRaw), http.MethodPost, "/vend/validate", true, opts...)
@@ -299,13 +431,19 @@ func (c *client) VendValidate(ctx context.Context, body VendValidateBody, opts .
 		VendValidateResp
 		Msg string `json:"msg"`
 	}
-	statusCode, status, err := httpDo(cl, req, &res, true)
+	statusCode, status, err := c.do(cl, req, &res)
 	if err != nil {
 		return nil, err
 	}
 	switch statusCode {
 	case http.StatusOK:
 		v := res.VendValidateResp
+		c.record(ctx, JournalEntry{
+			TransactionId:         v.Data.TransactionId,
+			Step:                  JournalValidateStep,
+			VerticalId:            body.VerticalId,
+			CustomerAccountNumber: body.CustomerAccountNumber,
+		}, opts...)
 		return &v, nil
 	case http.StatusBadRequest:
 		return nil, ValidationError(res.Msg)
@@ -324,39 +462,120 @@ func (c *client) VendValidate(ctx context.Context, body VendValidateBody, opts .
 	}
 }
 
-func (c *client) VendExecute(ctx context.Context, body VendExecuteBody, opts ...Option) (*VendExecuteResp, error) {
+func (c *client) VendExecute(ctx context.Context, body VendExecuteBody, opts ...Option) (out *VendExecuteResp, err error) {
+
+	ctx, end := c.startCall(ctx, callInfo{endpoint: "/vend/execute", transactionId: body.TransactionId, verticalId: body.VerticalId}, opts...)
+	defer func() { end(out, err) }()
+
+	err = body.Validate()
+	if err != nil {
+		return nil, err
+	}
+	// Send the number VendValidate sent.
+	if c.validatesAccountNumbers(opts) {
+		body.CustomerAccountNumber, err = ValidateAccountNumber(body.VerticalId, body.CustomerAccountNumber)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = c.Profile().CanVend()
+	if err != nil {
+		return nil, err
+	}
+
+	entry := JournalEntry{
+		TransactionId:         body.TransactionId,
+		Step:                  JournalExecuteStep,
+		State:                 TransactionInitiatedtate,
+		VerticalId:            body.VerticalId,
+		CustomerAccountNumber: body.CustomerAccountNumber,
+		Amount:                body.Amount,
+		At:                    time.Now(),
+	}
+	// The intent must be durable before money moves.
+	if journal := c.journalFor(opts...); journal != nil {
+		err = journal.Record(ctx, entry)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	store := c.idempotencyStore
+	for _, opt := range opts {
+		if opt, ok := opt.(idempotencyStoreOption); ok {
+			store = opt.v
+		}
+	}
+
+	var (
+		res      *VendExecuteResp
+		definite bool
+	)
+	if store != nil {
+		res, err = c.idempotentVendExecute(ctx, store, body, opts...)
+		definite = !errors.Is(err, ErrAmbiguousExecution) && !errors.Is(err, ErrTransactionInFlight)
+	} else {
+		var statusCode int
+		res, statusCode, err = c.vendExecute(ctx, body, opts...)
+		definite = isDefiniteFailure(statusCode, err)
+	}
+
+	entry.At = time.Now()
+	switch {
+	case err == nil:
+		entry.State = TransactionPendingState
+	case definite:
+		entry.State, entry.Error = TransactionFailedState, err.Error()
+	default:
+		entry.Error = err.Error()
+	}
+	c.record(ctx, entry, opts...)
+	c.recordVendExecute(ctx, body.TransactionId, err, definite)
+	return res, err
+}
+
+// Calls the vend execute endpoint, the returned status code is zero when the API answer is unknown.
+// Errors occurring before the request is sent are wrapped in a notSentError.
+func (c *client) vendExecute(ctx context.Context, body VendExecuteBody, opts ...Option) (*VendExecuteResp, int, error) {
 
 	bodyRaw, _ := json.Marshal(body)
 	cl, req, err := c.setRequestParams(ctx, bytes.NewReader(bodyRaw), http.MethodPost, "/vend/execute", true, opts...)
 	if err != nil {
-		return nil, err
+		return nil, 0, notSentError{err}
 	}
 
 	var res struct {
 		VendExecuteResp
 		Msg string `json:"msg"`
 	}
-	statusCode, status, err := httpDo(cl, req, &res, true)
+	statusCode, status, err := c.do(cl, req, &res)
 	if err != nil {
-		return nil, err
+		// A successful answer which could not be decoded leaves the outcome unknown.
+		if statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices {
+			statusCode = 0
+		}
+		return nil, statusCode, err
 	}
 	switch statusCode {
 	case http.StatusOK, http.StatusAccepted:
 		v := res.VendExecuteResp
-		return &v, nil
+		return &v, statusCode, nil
 	case http.StatusPreconditionFailed:
 		c.debug("[efashevdsapigo] /vend/execute", "status", status, "message", res.Msg)
-		return nil, ErrProductOutOfStock
+		return nil, statusCode, ErrProductOutOfStock
 	case http.StatusFailedDependency:
 		c.debug("[efashevdsapigo] /vend/execute", "status", status, "message", res.Msg)
-		return nil, ErrInsufficientBalance
+		return nil, statusCode, ErrInsufficientBalance
 	default:
 		c.debug("[efashevdsapigo] /vend/execute", "status", status, "message", res.Msg)
-		return nil, errors.New(res.Msg)
+		return nil, statusCode, errors.New(res.Msg)
 	}
 }
 
-func (c *client) VendTransactionStatus(ctx context.Context, transactionId string, opts ...Option) (*VendTransactionStatusResp, error) {
+func (c *client) VendTransactionStatus(ctx context.Context, transactionId string, opts ...Option) (out *VendTransactionStatusResp, err error) {
+
+	ctx, end := c.startCall(ctx, callInfo{endpoint: "/vend/{id}/status", transactionId: transactionId}, opts...)
+	defer func() { end(out, err) }()
 
 	path := fmt.Sprintf("/vend/%s/status", transactionId)
 	cl, req, err := c.setRequestParams(ctx, nil, http.MethodGet, path, true, opts...)
@@ -368,13 +587,20 @@ func (c *client) VendTransactionStatus(ctx context.Context, transactionId string
 		VendTransactionStatusResp
 		Msg string `json:"msg"`
 	}
-	statusCode, status, err := httpDo(cl, req, &res, true)
+	statusCode, status, err := c.do(cl, req, &res)
 	if err != nil {
 		return nil, err
 	}
 	switch statusCode {
 	case http.StatusOK, http.StatusAccepted:
 		v := res.VendTransactionStatusResp
+		c.record(ctx, JournalEntry{
+			TransactionId:         transactionId,
+			Step:                  JournalStatusStep,
+			State:                 v.Data.TransactionStatusId,
+			CustomerAccountNumber: v.Data.CustomerAccountNumber,
+			Amount:                v.Data.Amount,
+		}, opts...)
 		return &v, nil
 	case http.StatusNotFound:
 		c.debug(fmt.Sprintf("[efashevdsapigo] %s", path), "status", status, "message", res.Msg)
@@ -385,7 +611,15 @@ func (c *client) VendTransactionStatus(ctx context.Context, transactionId string
 	}
 }
 
-func (c *client) RepeatTransaction(ctx context.Context, transactionId string, opts ...Option) (*VendExecuteResp, error) {
+func (c *client) RepeatTransaction(ctx context.Context, transactionId string, opts ...Option) (out *VendExecuteResp, err error) {
+
+	ctx, end := c.startCall(ctx, callInfo{endpoint: "/trx/history/{id}/repeat", transactionId: transactionId}, opts...)
+	defer func() { end(out, err) }()
+
+	err = c.Profile().CanVend()
+	if err != nil {
+		return nil, err
+	}
 
 	path := fmt.Sprintf("/trx/history/%s/repeat", transactionId)
 	cl, req, err := c.setRequestParams(ctx, nil, http.MethodPost, path, true, opts...)
@@ -397,7 +631,7 @@ func (c *client) RepeatTransaction(ctx context.Context, transactionId string, op
 		VendExecuteResp
 		Msg string `json:"msg"`
 	}
-	statusCode, status, err := httpDo(cl, req, &res, true)
+	statusCode, status, err := c.do(cl, req, &res)
 	if err != nil {
 		return nil, err
 	}
@@ -414,27 +648,36 @@ func (c *client) RepeatTransaction(ctx context.Context, transactionId string, op
 	}
 }
 
-func (c *client) ElectricityTokens(ctx context.Context, meterNo string, tokensCount int, opts ...Option) (*ElectricityTokenResp, error) {
+func (c *client) ElectricityTokens(ctx context.Context, meterNo string, tokensCount int, opts ...Option) (out *ElectricityTokenResp, err error) {
+
+	ctx, end := c.startCall(ctx, callInfo{endpoint: "/electricity/tokens", verticalId: ElectricityVerticalId}, opts...)
+	defer func() { end(out, err) }()
 
 	meterNo = strings.TrimSpace(meterNo)
 	if meterNo == "" {
 		return nil, ValidationError("meter number is required")
 	}
-	if tokensCount <= 0 || tokensCount > 10 {
-		tokensCount = 10
+	if tokensCount == 0 {
+		tokensCount = MaxElectricityTokensCount
+	}
+	if tokensCount < 0 || tokensCount > MaxElectricityTokensCount {
+		return nil, ValidationError(fmt.Sprintf("tokens count must be between 1 and %d", MaxElectricityTokensCount))
 	}
 
 	cl, req, err := c.setRequestParams(ctx, nil, http.MethodGet, "/electricity/tokens", true, opts...)
 	if err != nil {
 		return nil, err
 	}
-	req.URL, _ = url.Parse(fmt.Sprintf("%s?meterNo=%s&numTokens=%d", req.URL, meterNo, tokensCount))
+	req.URL.RawQuery = url.Values{
+		"meterNo":   {meterNo},
+		"numTokens": {strconv.Itoa(tokensCount)},
+	}.Encode()
 
 	var res struct {
 		ElectricityTokenResp
 		Msg string `json:"msg"`
 	}
-	statusCode, status, err := httpDo(cl, req, &res, true)
+	statusCode, status, err := c.do(cl, req, &res)
 	if err != nil {
 		return nil, err
 	}
@@ -443,7 +686,73 @@ func (c *client) ElectricityTokens(ctx context.Context, meterNo string, tokensCo
 		v := res.ElectricityTokenResp
 		return &v, nil
 	default:
-		c.debug(fmt.Sprintf("[efashevdsapigo] %s", req.URL.RawPath), "status", status, "message", res.Msg)
+		c.debug("[efashevdsapigo] /electricity/tokens", "status", status, "message", res.Msg)
+		return nil, errors.New(res.Msg)
+	}
+}
+
+func (c *client) ListTransactions(ctx context.Context, filter TransactionFilter, opts ...Option) (out *ListTransactionsResp, err error) {
+
+	ctx, end := c.startCall(ctx, callInfo{endpoint: "/trx/history"}, opts...)
+	defer func() { end(out, err) }()
+
+	cl, req, err := c.setRequestParams(ctx, nil, http.MethodGet, "/trx/history", true, opts...)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = filter.query().Encode()
+
+	var res struct {
+		ListTransactionsResp
+		Msg string `json:"msg"`
+	}
+	statusCode, status, err := c.do(cl, req, &res)
+	if err != nil {
+		return nil, err
+	}
+	switch statusCode {
+	case http.StatusOK:
+		v := res.ListTransactionsResp
+		return &v, nil
+	case http.StatusBadRequest:
+		return nil, ValidationError(res.Msg)
+	case http.StatusUnauthorized:
+		c.debug("[efashevdsapigo] /trx/history", "status", status, "message", res.Msg)
+		return nil, ErrUnauthorized
+	default:
+		c.debug("[efashevdsapigo] /trx/history", "status", status, "message", res.Msg)
+		return nil, errors.New(res.Msg)
+	}
+}
+
+func (c *client) GetTransaction(ctx context.Context, transactionId string, opts ...Option) (out *GetTransactionResp, err error) {
+
+	ctx, end := c.startCall(ctx, callInfo{endpoint: "/trx/history/{id}", transactionId: transactionId}, opts...)
+	defer func() { end(out, err) }()
+
+	path := fmt.Sprintf("/trx/history/%s", url.PathEscape(transactionId))
+	cl, req, err := c.setRequestParams(ctx, nil, http.MethodGet, path, true, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		GetTransactionResp
+		Msg string `json:"msg"`
+	}
+	statusCode, status, err := c.do(cl, req, &res)
+	if err != nil {
+		return nil, err
+	}
+	switch statusCode {
+	case http.StatusOK:
+		v := res.GetTransactionResp
+		return &v, nil
+	case http.StatusNotFound:
+		c.debug(fmt.Sprintf("[efashevdsapigo] %s", path), "status", status, "message", res.Msg)
+		return nil, ErrTransactionNotFound
+	default:
+		c.debug(fmt.Sprintf("[efashevdsapigo] %s", path), "status", status, "message", res.Msg)
 		return nil, errors.New(res.Msg)
 	}
 }
@@ -455,6 +764,7 @@ func (c *client) setRequestParams(ctx context.Context, body io.Reader, method, p
 		cl          = c.client
 		customHd    http.Header
 		updateToken = c.autoUpdateToken
+		call        = callParams{endpoint: endpointName(path), limiter: c.limiter}
 	)
 
 	for _, opt := range opts {
@@ -467,6 +777,8 @@ func (c *client) setRequestParams(ctx context.Context, body io.Reader, method, p
 			customHd = opt.v
 		case disableAutoUpdatingTokenOption:
 			updateToken = !bool(opt)
+		case rateLimiterOption:
+			call.limiter = opt.v
 		}
 	}
 
@@ -477,20 +789,125 @@ func (c *client) setRequestParams(ctx context.Context, body io.Reader, method, p
 		}
 	}
 
+	ctx = context.WithValue(ctx, callParamsKey{}, call)
 	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
 	if err != nil {
 		return nil, nil, err
 	}
 	if shouldAuth {
+		c.tokenMu.RLock()
 		addBearerToken(req.Header, c.accessToken)
+		c.tokenMu.RUnlock()
 	}
 	setHeaders(req.Header, customHd)
 	return cl, req, nil
 }
 
+// Parameters of an API call carried by its request context from setRequestParams to do.
+type callParams struct {
+	endpoint string
+	limiter  *RateLimiter
+}
+
+type callParamsKey struct{}
+
+// Send the request and decode its JSON response body into jsonOut.
+// Every API call goes through it, so are the circuit breaker, rate limiting, 429 handling and request metrics.
+func (c *client) do(cl *http.Client, req *http.Request, jsonOut any) (statusCode int, statusText string, err error) {
+
+	call, _ := req.Context().Value(callParamsKey{}).(callParams)
+	var (
+		start            time.Time
+		reqBody, resBody []byte
+	)
+	if c.logs != nil {
+		if c.logs.bodies {
+			reqBody = requestBody(req)
+		}
+		defer func() {
+			var d time.Duration
+			if !start.IsZero() {
+				d = time.Since(start)
+			}
+			c.logs.logRequest(req, call, statusCode, d, err, reqBody, resBody)
+		}()
+	}
+
+	hooks, _ := req.Context().Value(callHooksKey{}).(*callHooks)
+	if hooks != nil {
+		hooks.request(req, call)
+	}
+
+	// The status endpoint is how an open breaker probes the API, so it is never short-circuited.
+	breaker := c.breaker
+	if call.endpoint == "/status" {
+		breaker = nil
+	}
+	if breaker != nil && !breaker.allow() {
+		return 0, "", notSentError{ErrAPIDown}
+	}
+	if call.limiter != nil {
+		err = call.limiter.Wait(req.Context(), call.endpoint)
+		if err != nil {
+			if breaker != nil {
+				breaker.release()
+			}
+			return 0, "", notSentError{err}
+		}
+	}
+
+	if c.telemetry != nil {
+		c.telemetry.inject(req.Context(), req.Header)
+	}
+	start = time.Now()
+	res, err := cl.Do(req)
+	code := 0
+	if res != nil {
+		code = res.StatusCode
+	}
+	if hooks != nil {
+		hooks.statusCode = code
+	}
+	if c.telemetry != nil {
+		c.telemetry.recordRequest(req.Context(), call.endpoint, req.Method, code, time.Since(start))
+	}
+	switch {
+	case breaker == nil:
+	case err != nil && errors.Is(req.Context().Err(), context.Canceled):
+		// The caller gave up, the call tells nothing about the API.
+		breaker.release()
+	default:
+		breaker.record(isBreakerFailure(req.Context(), code, err))
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusTooManyRequests {
+		d := retryAfter(res.Header)
+		if call.limiter != nil {
+			call.limiter.Backoff(call.endpoint, d)
+		}
+		c.debug(fmt.Sprintf("[efashevdsapigo] %s", call.endpoint), "status", res.Status, "retryAfter", d)
+		return res.StatusCode, res.Status, &RateLimitError{Endpoint: call.endpoint, RetryAfter: d}
+	}
+	if c.logs == nil || !c.logs.bodies {
+		return res.StatusCode, res.Status, readJSON(res.Body, jsonOut)
+	}
+	resBody, err = io.ReadAll(res.Body)
+	if err != nil {
+		return res.StatusCode, res.Status, err
+	}
+	return res.StatusCode, res.Status, json.Unmarshal(resBody, jsonOut)
+}
+
 func (c *client) debug(msg string, args ...any) {
 
 	if c.debugger != nil {
 		c.debugger.Debug(msg, args...)
 	}
+	if c.logs != nil {
+		c.logs.logger.Debug(msg, args...)
+	}
 }
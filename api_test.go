@@ -0,0 +1,134 @@
+package efashevdsapigo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type testAPI struct {
+	executeStatus int
+	executeDelay  time.Duration
+	// The Retry-After header of the execute answer.
+	retryAfter string
+	// The trxStatusId answered by the status endpoint, it answers 404 when empty.
+	trxState string
+
+	executes  atomic.Int32
+	statuses  atomic.Int32
+	refreshes atomic.Int32
+}
+
+func (a *testAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	switch r.URL.Path {
+	case "/auth":
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}).SignedString([]byte("secret"))
+		fmt.Fprintf(w, `{"data": {"accessToken": %q, "refreshToken": %q}}`, token, token)
+	case "/refresh-token":
+		a.refreshes.Add(1)
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}).SignedString([]byte("secret"))
+		fmt.Fprintf(w, `{"data": {"accessToken": %q, "refreshToken": %q}}`, token, token)
+	case "/vend/execute":
+		a.executes.Add(1)
+		time.Sleep(a.executeDelay)
+		if a.retryAfter != "" {
+			w.Header().Set("Retry-After", a.retryAfter)
+		}
+		w.WriteHeader(a.executeStatus)
+		fmt.Fprint(w, `{"msg": "execute", "data": {"pollEndpoint": "/vend/trx-1/status", "retryAfterSecs": 1}}`)
+	case "/vend/trx-1/status":
+		a.statuses.Add(1)
+		if a.trxState == "" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"msg": "not found"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"data": {"trxId": "trx-1", "trxStatusId": %q}}`, a.trxState)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestClient(t *testing.T, api *testAPI, opts ...Option) Client {
+
+	t.Helper()
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewClient(context.Background(), "key", "secret", append([]Option{WithBaseURLOption(u)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestConcurrentTokenRenewal(t *testing.T) {
+
+	api := &testAPI{trxState: TransactionSuccessedState}
+	c := newTestClient(t, api).(*client)
+	c.tokenMu.Lock()
+	c.accessTokenExpiresAt = time.Now().Add(-time.Minute)
+	c.tokenMu.Unlock()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.VendTransactionStatus(context.Background(), "trx-1")
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := api.refreshes.Load(); n != 1 {
+		t.Fatalf("token refreshed %d times, want 1", n)
+	}
+}
+
+func TestVendExecuteNormalizesAccountNumber(t *testing.T) {
+
+	api := &testAPI{executeStatus: http.StatusOK}
+	var sent string
+	c := newTestClient(t, api, WithAccountNumberValidationOption(true), WithOnRequestOption(func(_ context.Context, req RequestInfo) {
+		if req.Endpoint == "/vend/execute" {
+			sent = req.Body
+		}
+	}))
+
+	body := testExecuteBody
+	body.CustomerAccountNumber = "+250 780-000-000"
+	_, err := c.VendExecute(context.Background(), body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sent, `"customerAccountNumber":"0780000000"`) {
+		t.Fatalf("vend execute body = %s, want the normalized account number", sent)
+	}
+
+	validated := &VendValidateResp{}
+	validated.Data.TransactionId = body.TransactionId
+	validated.Data.VerticalId = body.VerticalId
+	validated.Data.CustomerAccountNumber = "0780000000"
+	if err := body.ValidateAgainst(validated); err != nil {
+		t.Fatalf("ValidateAgainst() error = %v, want nil", err)
+	}
+}
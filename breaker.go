@@ -0,0 +1,194 @@
+package efashevdsapigo
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultBreakerThreshold     = 5
+	DefaultBreakerProbeInterval = 30 * time.Second
+)
+
+type BreakerState string
+
+const (
+	BreakerClosed BreakerState = "closed"
+	// Calls are short-circuited with ErrAPIDown while the API is probed.
+	BreakerOpen BreakerState = "open"
+	// A probe succeeded, a single trial call closes the breaker on success or opens it again on failure.
+	// Other calls are short-circuited until the trial is recorded.
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// CircuitBreaker stops calling the API after consecutive server errors or timeouts, check WithCircuitBreakerOption.
+// While open it probes the API with Status and lets calls through again once the API is not in outage nor maintenance.
+// A breaker can be shared by clients of the same API.
+type CircuitBreaker struct {
+	threshold     int
+	probeInterval time.Duration
+	listeners     []func(from, to BreakerState)
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	lastProbe time.Time
+	probing   bool
+	// A half-open trial call is in flight.
+	trial  bool
+	prober func(ctx context.Context) (*StatusResp, error)
+}
+
+func NewCircuitBreaker(opts ...Option) *CircuitBreaker {
+
+	b := &CircuitBreaker{
+		threshold:     DefaultBreakerThreshold,
+		probeInterval: DefaultBreakerProbeInterval,
+		state:         BreakerClosed,
+	}
+	for _, opt := range opts {
+		switch opt := opt.(type) {
+		case breakerThresholdOption:
+			if opt > 0 {
+				b.threshold = int(opt)
+			}
+		case breakerProbeIntervalOption:
+			if opt > 0 {
+				b.probeInterval = time.Duration(opt)
+			}
+		case breakerListenerOption:
+			b.listeners = append(b.listeners, opt.v)
+		}
+	}
+	return b
+}
+
+func (b *CircuitBreaker) State() BreakerState {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Whether a call can go through, an open breaker starts a probe once the probe interval has passed
+// and a half-open one lets a single trial call through. An allowed call must be recorded or released.
+func (b *CircuitBreaker) allow() bool {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerClosed:
+		return true
+	case BreakerHalfOpen:
+		if b.trial {
+			return false
+		}
+		b.trial = true
+		return true
+	}
+	if !b.probing && b.prober != nil && time.Since(b.lastProbe) >= b.probeInterval {
+		b.probing = true
+		go b.probe()
+	}
+	return false
+}
+
+func (b *CircuitBreaker) probe() {
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.probeInterval)
+	defer cancel()
+	res, err := b.prober(ctx)
+
+	b.mu.Lock()
+	b.probing = false
+	b.lastProbe = time.Now()
+	up := err == nil && res.Status != APIMajorOutageStatus && res.Status != APIMaintenanceStatus
+	if !up || b.state != BreakerOpen {
+		b.mu.Unlock()
+		return
+	}
+	b.trial = false
+	notify := b.setState(BreakerHalfOpen)
+	b.mu.Unlock()
+	notify()
+}
+
+// Record the outcome of a call.
+func (b *CircuitBreaker) record(failed bool) {
+
+	b.mu.Lock()
+	b.trial = false
+	var notify func()
+	switch {
+	case !failed:
+		b.failures = 0
+		notify = b.setState(BreakerClosed)
+	case b.state == BreakerHalfOpen:
+		notify = b.open()
+	default:
+		b.failures++
+		if b.failures >= b.threshold {
+			notify = b.open()
+		}
+	}
+	b.mu.Unlock()
+	if notify != nil {
+		notify()
+	}
+}
+
+// Forget an allowed call which tells nothing about the API, e.g it was not sent, so another trial can go through.
+func (b *CircuitBreaker) release() {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+func (b *CircuitBreaker) open() func() {
+
+	b.failures = 0
+	b.lastProbe = time.Now()
+	return b.setState(BreakerOpen)
+}
+
+// Set the state with the lock held, the returned func notifies the listeners and must be called without the lock.
+func (b *CircuitBreaker) setState(to BreakerState) func() {
+
+	from := b.state
+	if from == to {
+		return func() {}
+	}
+	b.state = to
+	return func() {
+		for _, l := range b.listeners {
+			l(from, to)
+		}
+	}
+}
+
+func (b *CircuitBreaker) setProber(prober func(ctx context.Context) (*StatusResp, error)) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.prober == nil {
+		b.prober = prober
+	}
+}
+
+// Whether a call outcome counts against the API: server errors and timeouts, including the caller deadline, not the caller canceling.
+func isBreakerFailure(ctx context.Context, statusCode int, err error) bool {
+
+	if statusCode >= http.StatusInternalServerError {
+		return true
+	}
+	if err == nil || statusCode != 0 || errors.Is(ctx.Err(), context.Canceled) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,81 @@
+package efashevdsapigo
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func halfOpenBreaker(t *testing.T) *CircuitBreaker {
+
+	t.Helper()
+	b := NewCircuitBreaker(WithBreakerThresholdOption(1), WithBreakerProbeIntervalOption(time.Millisecond))
+	b.setProber(func(context.Context) (*StatusResp, error) {
+		return &StatusResp{Status: APIOperationalStatus}, nil
+	})
+	b.record(true)
+	deadline := time.Now().Add(5 * time.Second)
+	for b.State() != BreakerHalfOpen {
+		if time.Now().After(deadline) {
+			t.Fatalf("breaker is %s, want %s", b.State(), BreakerHalfOpen)
+		}
+		b.allow()
+		time.Sleep(time.Millisecond)
+	}
+	return b
+}
+
+func TestBreakerHalfOpenSingleTrial(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		failed bool
+		want   BreakerState
+	}{
+		{name: "success", want: BreakerClosed},
+		{name: "failure", failed: true, want: BreakerOpen},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			b := halfOpenBreaker(t)
+			if !b.allow() {
+				t.Fatal("trial call short-circuited")
+			}
+			for range 3 {
+				if b.allow() {
+					t.Fatal("call allowed while the trial is in flight")
+				}
+			}
+			b.record(tt.failed)
+			if s := b.State(); s != tt.want {
+				t.Fatalf("breaker is %s, want %s", s, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreakerHalfOpenRelease(t *testing.T) {
+
+	b := halfOpenBreaker(t)
+	if !b.allow() {
+		t.Fatal("trial call short-circuited")
+	}
+	b.release()
+	if !b.allow() {
+		t.Fatal("trial call short-circuited after the previous trial was released")
+	}
+	if s := b.State(); s != BreakerHalfOpen {
+		t.Fatalf("breaker is %s, want %s", s, BreakerHalfOpen)
+	}
+}
+
+func TestBreakerProbeIntervalOption(t *testing.T) {
+
+	for _, d := range []time.Duration{0, -time.Second} {
+		b := NewCircuitBreaker(WithBreakerProbeIntervalOption(d))
+		if b.probeInterval != DefaultBreakerProbeInterval {
+			t.Errorf("probe interval of %s = %s, want %s", d, b.probeInterval, DefaultBreakerProbeInterval)
+		}
+	}
+}
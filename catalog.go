@@ -0,0 +1,260 @@
+package efashevdsapigo
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultCatalogTTL   = 10 * time.Minute
+	DefaultCatalogStale = time.Hour
+)
+
+type CatalogEventType string
+
+const (
+	VerticalAddedEvent       CatalogEventType = "added"
+	VerticalRemovedEvent     CatalogEventType = "removed"
+	VerticalDeactivatedEvent CatalogEventType = "deactivated"
+)
+
+// Change detected by a Catalog between two successive verticals listings.
+type CatalogEvent struct {
+	Type CatalogEventType
+	// The vertical as last seen, for removed verticals this is the previously cached one.
+	Vertical Vertical
+}
+
+// Catalog caches the verticals list returned by ListVerticals.
+// A fetched list is fresh for the TTL, then served as stale while it is revalidated in background,
+// once the stale window has passed lookups block until a new list is fetched.
+type Catalog struct {
+	client    Client
+	ttl       time.Duration
+	stale     time.Duration
+	listeners []func(CatalogEvent)
+	debugger  Debugger
+	opts      []Option
+
+	mu        sync.Mutex
+	verticals []Vertical
+	fetchedAt time.Time
+	// The listing in progress, concurrent refreshes wait for it instead of listing again.
+	fetching *catalogFetch
+	// Events not yet emitted to the listeners, in detection order.
+	events []CatalogEvent
+
+	// Serializes the listeners calls, it is never held with mu.
+	notifyMu sync.Mutex
+}
+
+type catalogFetch struct {
+	done chan struct{}
+	err  error
+}
+
+func NewCatalog(client Client, opts ...Option) *Catalog {
+
+	c := &Catalog{
+		client: client,
+		ttl:    DefaultCatalogTTL,
+		stale:  DefaultCatalogStale,
+	}
+
+	for _, opt := range opts {
+		switch opt := opt.(type) {
+		case catalogTTLOption:
+			c.ttl = time.Duration(opt)
+		case catalogStaleOption:
+			c.stale = time.Duration(opt)
+		case catalogListenerOption:
+			c.listeners = append(c.listeners, opt.v)
+		case debugOption:
+			c.debugger = opt.v
+		default:
+			c.opts = append(c.opts, opt)
+		}
+	}
+	return c
+}
+
+// All cached verticals regardless of their status.
+func (c *Catalog) Verticals(ctx context.Context) ([]Vertical, error) {
+
+	verticals, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append([]Vertical(nil), verticals...), nil
+}
+
+// Cached verticals with an active status.
+func (c *Catalog) ActiveVerticals(ctx context.Context) ([]Vertical, error) {
+
+	verticals, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var res []Vertical
+	for _, v := range verticals {
+		if v.Status == VerticalActiveStatus {
+			res = append(res, v)
+		}
+	}
+	return res, nil
+}
+
+// Lookup a vertical by its id, ErrVerticalNotFound is returned if it is not listed.
+func (c *Catalog) Vertical(ctx context.Context, verticalId string) (*Vertical, error) {
+
+	verticals, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range verticals {
+		if v.Id == verticalId {
+			return &v, nil
+		}
+	}
+	return nil, ErrVerticalNotFound
+}
+
+// Active verticals which support the delivery method.
+func (c *Catalog) VerticalsByDeliveryMethod(ctx context.Context, deliveryMethodId string) ([]Vertical, error) {
+
+	verticals, err := c.ActiveVerticals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var res []Vertical
+	for _, v := range verticals {
+		for _, m := range v.DeliveryMethods {
+			if m.Id == deliveryMethodId {
+				res = append(res, v)
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+// Fetch the verticals list regardless of the cache age, concurrent refreshes share a single ListVerticals call.
+func (c *Catalog) Refresh(ctx context.Context) error {
+
+	c.mu.Lock()
+	f := c.fetch(ctx)
+	c.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Start listing the verticals unless a listing is in progress, c.mu must be held.
+// The listing is not canceled with ctx as other callers may be waiting for it.
+func (c *Catalog) fetch(ctx context.Context) *catalogFetch {
+
+	if c.fetching != nil {
+		return c.fetching
+	}
+	f := &catalogFetch{done: make(chan struct{})}
+	c.fetching = f
+	go func() {
+		res, err := c.client.ListVerticals(context.WithoutCancel(ctx), c.opts...)
+
+		c.mu.Lock()
+		if err == nil {
+			c.events = append(c.events, diffVerticals(c.verticals, res.Data, !c.fetchedAt.IsZero())...)
+			c.verticals = res.Data
+			c.fetchedAt = time.Now()
+		}
+		f.err = err
+		c.fetching = nil
+		c.mu.Unlock()
+		close(f.done)
+
+		if err != nil && c.debugger != nil {
+			c.debugger.Debug("[efashevdsapigo] catalog refresh failed.", "error", err)
+		}
+		c.notify()
+	}()
+	return f
+}
+
+// Emit the pending events, listeners may call the catalog as no catalog lock is held but notifyMu.
+func (c *Catalog) notify() {
+
+	c.notifyMu.Lock()
+	defer c.notifyMu.Unlock()
+	for {
+		c.mu.Lock()
+		events := c.events
+		c.events = nil
+		c.mu.Unlock()
+		if len(events) == 0 {
+			return
+		}
+		for _, e := range events {
+			for _, l := range c.listeners {
+				l(e)
+			}
+		}
+	}
+}
+
+func (c *Catalog) load(ctx context.Context) ([]Vertical, error) {
+
+	c.mu.Lock()
+	age := time.Since(c.fetchedAt)
+	switch {
+	case !c.fetchedAt.IsZero() && age < c.ttl:
+		verticals := c.verticals
+		c.mu.Unlock()
+		return verticals, nil
+	case !c.fetchedAt.IsZero() && age < c.ttl+c.stale:
+		verticals := c.verticals
+		c.fetch(ctx)
+		c.mu.Unlock()
+		return verticals, nil
+	}
+	c.mu.Unlock()
+
+	err := c.Refresh(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.verticals, nil
+}
+
+func diffVerticals(prev, next []Vertical, notifyAdded bool) []CatalogEvent {
+
+	var (
+		events []CatalogEvent
+		seen   = make(map[string]Vertical, len(prev))
+	)
+	for _, v := range prev {
+		seen[v.Id] = v
+	}
+	for _, v := range next {
+		old, ok := seen[v.Id]
+		delete(seen, v.Id)
+		switch {
+		case !ok && notifyAdded:
+			events = append(events, CatalogEvent{Type: VerticalAddedEvent, Vertical: v})
+		case ok && old.Status == VerticalActiveStatus && v.Status != VerticalActiveStatus:
+			events = append(events, CatalogEvent{Type: VerticalDeactivatedEvent, Vertical: v})
+		}
+	}
+	for _, v := range prev {
+		if _, ok := seen[v.Id]; ok {
+			events = append(events, CatalogEvent{Type: VerticalRemovedEvent, Vertical: v})
+		}
+	}
+	return events
+}
@@ -0,0 +1,62 @@
+package efashevdsapigo
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Lists two verticals on the first call and one afterwards.
+type testCatalogClient struct {
+	Client
+	lists atomic.Int32
+}
+
+func (c *testCatalogClient) ListVerticals(context.Context, ...Option) (*ListVerticalsResp, error) {
+
+	var a, b Vertical
+	a.Id, a.Status = "a", VerticalActiveStatus
+	b.Id, b.Status = "b", VerticalActiveStatus
+	res := &ListVerticalsResp{Data: []Vertical{a, b}}
+	if c.lists.Add(1) > 1 {
+		res.Data = res.Data[:1]
+	}
+	return res, nil
+}
+
+func TestCatalogListenerCallsCatalog(t *testing.T) {
+
+	var (
+		catalog *Catalog
+		events  = make(chan CatalogEvent, 1)
+	)
+	client := &testCatalogClient{}
+	catalog = NewCatalog(client, WithCatalogListenerOption(func(e CatalogEvent) {
+		if err := catalog.Refresh(context.Background()); err != nil {
+			t.Error(err)
+		}
+		if _, err := catalog.Verticals(context.Background()); err != nil {
+			t.Error(err)
+		}
+		events <- e
+	}))
+
+	if err := catalog.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := catalog.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-events:
+		if e.Type != VerticalRemovedEvent || e.Vertical.Id != "b" {
+			t.Fatalf("event = %s %s, want %s b", e.Type, e.Vertical.Id, VerticalRemovedEvent)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("listener deadlocked")
+	}
+	if n := client.lists.Load(); n != 3 {
+		t.Fatalf("verticals listed %d times, want 3", n)
+	}
+}
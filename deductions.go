@@ -0,0 +1,175 @@
+package efashevdsapigo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Deductions rate types
+	PercentageRateType = "percentage"
+	FlatRateType       = "flat"
+)
+
+type DeductionKind string
+
+const (
+	CommissionDeduction DeductionKind = "commission"
+	VatDeduction        DeductionKind = "vat"
+	FeeDeduction        DeductionKind = "fee"
+	OtherDeduction      DeductionKind = "other"
+)
+
+// The kind of the deduction guessed from its name.
+func (d Deductions) Kind() DeductionKind {
+
+	name := strings.ToLower(d.DeductionName)
+	switch {
+	case strings.Contains(name, "commission"):
+		return CommissionDeduction
+	case strings.Contains(name, "vat") || strings.Contains(name, "tva"):
+		return VatDeduction
+	case strings.Contains(name, "fee") || strings.Contains(name, "levy") || strings.Contains(name, "regulatory"):
+		return FeeDeduction
+	default:
+		return OtherDeduction
+	}
+}
+
+// The numeric rate, a percentage rate of "2.5%", "2.5" or "2,5" is 2.5.
+// A comma is taken as the decimal separator, rates with both a comma and a dot are rejected.
+func (d Deductions) RateValue() (float64, error) {
+
+	v := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(d.Rate), "%"))
+	if v == "" {
+		return 0, nil
+	}
+	if strings.Contains(v, ",") {
+		if strings.Contains(v, ".") {
+			return 0, ValidationError(fmt.Sprintf("deduction %q: ambiguous rate %q", d.DeductionName, d.Rate))
+		}
+		v = strings.Replace(v, ",", ".", 1)
+	}
+	rate, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, ValidationError(fmt.Sprintf("deduction %q: invalid rate %q", d.DeductionName, d.Rate))
+	}
+	return rate, nil
+}
+
+// The deducted amount, computed from the rate on the base amount when the API did not report it.
+func (d Deductions) Amount(base float64) (float64, error) {
+
+	if d.AmountDeducted != 0 {
+		return d.AmountDeducted, nil
+	}
+	rate, err := d.RateValue()
+	if err != nil {
+		return 0, err
+	}
+	switch d.RateType {
+	case PercentageRateType:
+		return base * rate / 100, nil
+	case FlatRateType:
+		return rate, nil
+	default:
+		return 0, ValidationError(fmt.Sprintf("deduction %q: unknown rate type %q", d.DeductionName, d.RateType))
+	}
+}
+
+type DeductionTotals struct {
+	// Number of successful transactions.
+	Count int
+	// Sum of the transactions amount.
+	Amount float64
+	// Deductions of the service provider, from SpVendInfo.
+	Provider DeductionBreakdown
+	// Deductions of the agency, from OurVendInfo. Its commission is the commission earned by the agency.
+	Agency DeductionBreakdown
+}
+
+type DeductionBreakdown struct {
+	Commission float64
+	Vat        float64
+	Fees       float64
+	Other      float64
+}
+
+func (t *DeductionBreakdown) add(kind DeductionKind, amount float64) {
+
+	switch kind {
+	case CommissionDeduction:
+		t.Commission += amount
+	case VatDeduction:
+		t.Vat += amount
+	case FeeDeduction:
+		t.Fees += amount
+	default:
+		t.Other += amount
+	}
+}
+
+// Groups transactions when aggregating deductions.
+type DeductionGroupKey func(trx TransactionRecord) string
+
+var (
+	ByVertical DeductionGroupKey = func(trx TransactionRecord) string { return trx.VerticalId }
+	ByBranch   DeductionGroupKey = func(trx TransactionRecord) string {
+		if trx.BranchName != "" {
+			return trx.BranchName
+		}
+		return trx.OurVendInfo.BranchName
+	}
+	ByStaff DeductionGroupKey = func(trx TransactionRecord) string {
+		if trx.StaffName != "" {
+			return trx.StaffName
+		}
+		return trx.OurVendInfo.StaffName
+	}
+	// Groups by the creation day formatted as 2006-01-02.
+	ByDay DeductionGroupKey = func(trx TransactionRecord) string {
+		if t, ok := parseTimestamp(trx.CreatedAt); ok {
+			return t.Format("2006-01-02")
+		}
+		if len(trx.CreatedAt) >= 10 {
+			return trx.CreatedAt[:10]
+		}
+		return trx.CreatedAt
+	}
+)
+
+// Sum the service provider and agency deductions of successful transactions per group, apart from each other.
+// Deductions without reported amount are computed from their rate on the transaction amount.
+func AggregateDeductions(trxs []TransactionRecord, key DeductionGroupKey) (map[string]*DeductionTotals, error) {
+
+	res := map[string]*DeductionTotals{}
+	for _, trx := range trxs {
+		if trx.TransactionStatusId != TransactionSuccessedState {
+			continue
+		}
+		k := key(trx)
+		totals, ok := res[k]
+		if !ok {
+			totals = &DeductionTotals{}
+			res[k] = totals
+		}
+		totals.Count++
+		totals.Amount += trx.Amount
+		for _, d := range trx.SpVendInfo.Deductions {
+			amount, err := d.Amount(trx.Amount)
+			if err != nil {
+				return nil, fmt.Errorf("transaction %s: %w", trx.TransactionId, err)
+			}
+			totals.Provider.add(d.Kind(), amount)
+		}
+		for _, d := range trx.OurVendInfo.OurDeductions {
+			amount, err := d.Amount(trx.Amount)
+			if err != nil {
+				return nil, fmt.Errorf("transaction %s: %w", trx.TransactionId, err)
+			}
+			totals.Agency.add(d.Kind(), amount)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,212 @@
+package efashevdsapigo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Decodes the raw extraInfo of a vend validate response into a vertical specific value.
+type ExtraInfoDecoder func(raw map[string]any) (any, error)
+
+var extraInfoDecoders = struct {
+	sync.RWMutex
+	m map[string]ExtraInfoDecoder
+}{
+	m: map[string]ExtraInfoDecoder{
+		TaxVerticalId:   func(raw map[string]any) (any, error) { return decodeTaxInfo(raw) },
+		WaterVerticalId: func(raw map[string]any) (any, error) { return decodeWaterInfo(raw) },
+	},
+}
+
+// Register the extraInfo decoder of a vertical, it replaces any decoder previously registered for it.
+func RegisterExtraInfoDecoder(verticalId string, decoder ExtraInfoDecoder) {
+
+	extraInfoDecoders.Lock()
+	defer extraInfoDecoders.Unlock()
+	extraInfoDecoders.m[verticalId] = decoder
+}
+
+// Decode extraInfo with the decoder registered for the response vertical.
+// When no decoder is registered the raw map is returned.
+func (r *VendValidateResp) DecodeExtraInfo() (any, error) {
+
+	extraInfoDecoders.RLock()
+	decoder, ok := extraInfoDecoders.m[r.Data.VerticalId]
+	extraInfoDecoders.RUnlock()
+	if !ok {
+		return r.Data.ExtraInfo, nil
+	}
+	if len(r.Data.ExtraInfo) == 0 {
+		return nil, ErrNoExtraInfo
+	}
+	return decoder(r.Data.ExtraInfo)
+}
+
+// Tax payment details returned in extraInfo for the tax vertical.
+type TaxInfo struct {
+	// The taxpayer Tax Identification Number.
+	TIN string `json:"tin"`
+	// The tax declaration validation id.
+	ValidateId string `json:"validate_id"`
+	// The payment reference to settle.
+	PayRef string `json:"pay_ref"`
+	// The tax center the declaration was filed at.
+	TaxCenter string `json:"tax_center"`
+	// The declaration date.
+	DecDate string `json:"dec_date"`
+	// Whether the declared amount must be paid in full.
+	IsFullPay bool   `json:"is_full_pay"`
+	TaxType   string `json:"tax_type"`
+}
+
+// Decoded and validated tax extraInfo, ErrNoExtraInfo is returned if the response is not for the tax vertical.
+func (r *VendValidateResp) TaxInfo() (*TaxInfo, error) {
+
+	if r.Data.VerticalId != TaxVerticalId || len(r.Data.ExtraInfo) == 0 {
+		return nil, ErrNoExtraInfo
+	}
+	return decodeTaxInfo(r.Data.ExtraInfo)
+}
+
+func decodeTaxInfo(raw map[string]any) (*TaxInfo, error) {
+
+	v := &TaxInfo{
+		TIN:        stringValue(raw["tin"]),
+		ValidateId: stringValue(raw["validate_id"]),
+		PayRef:     stringValue(raw["pay_ref"]),
+		TaxCenter:  stringValue(raw["tax_center"]),
+		DecDate:    stringValue(raw["dec_date"]),
+		IsFullPay:  boolValue(raw["is_full_pay"]),
+		TaxType:    stringValue(raw["tax_type"]),
+	}
+	if v.PayRef == "" {
+		return nil, ValidationError("tax extra info: pay_ref is missing")
+	}
+	if v.TIN == "" {
+		return nil, ValidationError("tax extra info: tin is missing")
+	}
+	return v, nil
+}
+
+// Water bill details returned in extraInfo for the water vertical.
+type WaterInfo struct {
+	CustomerName string `json:"customer_name"`
+	// The water utility customer number.
+	CustomerNo string `json:"customer_no"`
+	// The outstanding amount of the bill.
+	Arrears float64 `json:"arrears"`
+	// The bill due date as reported by the utility.
+	DueDate string `json:"due_date"`
+	// The utility branch the customer belongs to.
+	Branch string `json:"branch"`
+}
+
+// Decoded water extraInfo, ErrNoExtraInfo is returned if the response is not for the water vertical.
+func (r *VendValidateResp) WaterInfo() (*WaterInfo, error) {
+
+	if r.Data.VerticalId != WaterVerticalId || len(r.Data.ExtraInfo) == 0 {
+		return nil, ErrNoExtraInfo
+	}
+	return decodeWaterInfo(r.Data.ExtraInfo)
+}
+
+func decodeWaterInfo(raw map[string]any) (*WaterInfo, error) {
+
+	v := &WaterInfo{
+		CustomerName: stringValue(raw["customer_name"]),
+		CustomerNo:   stringValue(raw["customer_no"]),
+		DueDate:      stringValue(raw["due_date"]),
+		Branch:       stringValue(raw["branch"]),
+	}
+	if arrears := stringValue(raw["arrears"]); arrears != "" {
+		n, err := strconv.ParseFloat(arrears, 64)
+		if err != nil {
+			return nil, ValidationError(fmt.Sprintf("water extra info: arrears %q is not an amount", arrears))
+		}
+		v.Arrears = n
+	}
+	return v, nil
+}
+
+// Stock details of stocked voucher products, taken from localStockMgt, stockedPdts and stock.
+type StockInfo struct {
+	// Whether the stock is managed locally by the agency.
+	LocalStockMgt bool
+	Products      []StockedProduct
+	// The overall stock quantity when it is reported as a number.
+	Stock float64
+}
+
+type StockedProduct struct {
+	Id       string  `json:"id"`
+	Name     string  `json:"name"`
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+	Stock    float64 `json:"stock"`
+}
+
+// Decoded stock details, ErrNoExtraInfo is returned if the product is not stocked.
+func (r *VendValidateResp) StockInfo() (*StockInfo, error) {
+
+	if r.Data.LocalStockMgt == nil && r.Data.StockedPdts == nil && r.Data.Stock == nil {
+		return nil, ErrNoExtraInfo
+	}
+
+	v := &StockInfo{
+		LocalStockMgt: boolValue(r.Data.LocalStockMgt),
+	}
+	if r.Data.StockedPdts != nil {
+		raw, err := json.Marshal(r.Data.StockedPdts)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(raw, &v.Products)
+		if err != nil {
+			return nil, fmt.Errorf("stocked products: %w", err)
+		}
+	}
+	switch s := r.Data.Stock.(type) {
+	case float64:
+		v.Stock = s
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil, ValidationError(fmt.Sprintf("stock: %q is not a quantity", s))
+		}
+		v.Stock = n
+	}
+	return v, nil
+}
+
+func stringValue(v any) string {
+
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func boolValue(v any) bool {
+
+	switch v := v.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "1", "true", "y", "yes":
+			return true
+		}
+	}
+	return false
+}
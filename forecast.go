@@ -0,0 +1,93 @@
+package efashevdsapigo
+
+import (
+	"strconv"
+	"time"
+)
+
+// Agency deductions applied to vends keyed by product id, or by vertical id for a vertical wide schedule.
+// Amounts of the deductions are ignored, only their rate type and rate are applied.
+type CommissionSchedule map[string][]Deductions
+
+// Learn a schedule from past successful transactions, the agency deductions of the most recent transaction
+// of each product and vertical are kept. Flat deductions without rate get their amount as rate,
+// other deductions without rate get a percentage rate derived from their amount.
+func LearnCommissionSchedule(trxs []TransactionRecord) CommissionSchedule {
+
+	var (
+		res    = CommissionSchedule{}
+		latest = map[string]time.Time{}
+	)
+	for _, trx := range trxs {
+		if trx.TransactionStatusId != TransactionSuccessedState || len(trx.OurVendInfo.OurDeductions) == 0 {
+			continue
+		}
+		rates := make([]Deductions, 0, len(trx.OurVendInfo.OurDeductions))
+		for _, d := range trx.OurVendInfo.OurDeductions {
+			if _, err := d.RateValue(); d.Rate == "" || err != nil {
+				switch {
+				case d.RateType == FlatRateType:
+					d.Rate = strconv.FormatFloat(d.AmountDeducted, 'f', -1, 64)
+				case trx.Amount == 0:
+					continue
+				default:
+					d.RateType = PercentageRateType
+					d.Rate = strconv.FormatFloat(d.AmountDeducted/trx.Amount*100, 'f', -1, 64)
+				}
+			}
+			d.AmountDeducted = 0
+			rates = append(rates, d)
+		}
+		// Transactions with an unknown creation time are the oldest.
+		created, _ := trx.CreatedTime()
+		for _, k := range []string{trx.PdtId, trx.VerticalId} {
+			if k != "" && !created.Before(latest[k]) {
+				res[k], latest[k] = rates, created
+			}
+		}
+	}
+	return res
+}
+
+type CommissionForecast struct {
+	Amount float64
+	// Commission the agency is expected to earn.
+	Commission float64
+	// Other agency deductions, e.g VAT or fees on the commission.
+	Deductions float64
+	// What the vend costs the agency: the amount less the commission plus the other deductions.
+	NetCost float64
+	// The deductions applied, with their computed amount.
+	Items []Deductions
+}
+
+// Forecast the commission of vending the amount for the validated product,
+// the schedule of the product is used first then the one of the vertical.
+func (s CommissionSchedule) Forecast(resp *VendValidateResp, amount float64) (*CommissionForecast, error) {
+
+	rates, ok := s[resp.Data.PdtId]
+	if !ok {
+		rates, ok = s[resp.Data.VerticalId]
+	}
+	if !ok {
+		return nil, ErrNoCommissionRates
+	}
+
+	f := &CommissionForecast{Amount: amount}
+	for _, d := range rates {
+		d.AmountDeducted = 0
+		v, err := d.Amount(amount)
+		if err != nil {
+			return nil, err
+		}
+		d.AmountDeducted = v
+		f.Items = append(f.Items, d)
+		if d.Kind() == CommissionDeduction {
+			f.Commission += v
+		} else {
+			f.Deductions += v
+		}
+	}
+	f.NetCost = amount - f.Commission + f.Deductions
+	return f, nil
+}
@@ -0,0 +1,50 @@
+package efashevdsapigo
+
+import "testing"
+
+func testCommissionRecord(createdAt string, amount float64, deductions ...Deductions) TransactionRecord {
+
+	var trx TransactionRecord
+	trx.TransactionStatusId = TransactionSuccessedState
+	trx.PdtId, trx.VerticalId = "pdt-1", ElectricityVerticalId
+	trx.CreatedAt = createdAt
+	trx.Amount = amount
+	trx.OurVendInfo.OurDeductions = deductions
+	return trx
+}
+
+func TestLearnCommissionSchedule(t *testing.T) {
+
+	trxs := []TransactionRecord{
+		// Later than the next one although its timestamp sorts before as a string.
+		testCommissionRecord("2024-05-01T12:00:00Z", 1000,
+			Deductions{DeductionName: "Commission", RateType: PercentageRateType, AmountDeducted: 30},
+			Deductions{DeductionName: "Fee", RateType: FlatRateType, AmountDeducted: 50},
+		),
+		testCommissionRecord("2024-05-01T13:00:00+04:00", 1000,
+			Deductions{DeductionName: "Commission", RateType: PercentageRateType, Rate: "2"},
+		),
+	}
+	schedule := LearnCommissionSchedule(trxs)
+	rates := schedule["pdt-1"]
+	if len(rates) != 2 {
+		t.Fatalf("learned %d deductions, want those of the latest transaction: %+v", len(rates), rates)
+	}
+	if rates[0].RateType != PercentageRateType || rates[0].Rate != "3" {
+		t.Errorf("commission learned as %s %s, want percentage 3", rates[0].RateType, rates[0].Rate)
+	}
+	if rates[1].RateType != FlatRateType || rates[1].Rate != "50" {
+		t.Errorf("fee learned as %s %s, want flat 50", rates[1].RateType, rates[1].Rate)
+	}
+
+	resp := &VendValidateResp{}
+	resp.Data.PdtId = "pdt-1"
+	f, err := schedule.Forecast(resp, 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The flat fee does not scale with the amount.
+	if f.Items[0].AmountDeducted != 60 || f.Items[1].AmountDeducted != 50 {
+		t.Fatalf("forecast deductions %v and %v, want 60 and 50", f.Items[0].AmountDeducted, f.Items[1].AmountDeducted)
+	}
+}
@@ -0,0 +1,217 @@
+package efashevdsapigo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultHealthInterval = 30 * time.Second
+	DefaultHealthHistory  = 120
+)
+
+// APIHealth is the API status as reported by Status, or one of the health values observed client side.
+type APIHealth string
+
+const (
+	HealthUnknown       APIHealth = "unknown"
+	HealthOperational   APIHealth = APIOperationalStatus
+	HealthDegraded      APIHealth = APIDegradedStatus
+	HealthPartialOutage APIHealth = APIPartialOutageStatus
+	HealthMajorOutage   APIHealth = APIMajorOutageStatus
+	HealthMaintenance   APIHealth = APIMaintenanceStatus
+	// The status endpoint could not be reached.
+	HealthUnreachable APIHealth = "unreachable"
+	// The API is up but the client session is not valid.
+	HealthUnauthenticated APIHealth = "unauthenticated"
+	// The API is up but the client session could not be checked.
+	HealthSessionUnknown APIHealth = "session_unknown"
+)
+
+// Whether calls can be served, i.e the API is operational or degraded with a valid session.
+func (h APIHealth) Ready() bool {
+	return h == HealthOperational || h == HealthDegraded
+}
+
+type HealthSample struct {
+	At             time.Time     `json:"at"`
+	Health         APIHealth     `json:"health"`
+	StatusLatency  time.Duration `json:"statusLatency"`
+	SessionLatency time.Duration `json:"sessionLatency"`
+	Error          string        `json:"error,omitempty"`
+}
+
+// HealthMonitor periodically checks the API with Status and ValidateSession and keeps the latest samples.
+// It is an http.Handler answering 200 when the API is ready and 503 otherwise, suited to readiness probes.
+type HealthMonitor struct {
+	client     Client
+	interval   time.Duration
+	maxHistory int
+	listeners  []func(from, to APIHealth)
+
+	mu      sync.RWMutex
+	current APIHealth
+	history []HealthSample
+}
+
+func NewHealthMonitor(client Client, opts ...Option) *HealthMonitor {
+
+	m := &HealthMonitor{
+		client:     client,
+		interval:   DefaultHealthInterval,
+		maxHistory: DefaultHealthHistory,
+		current:    HealthUnknown,
+	}
+	for _, opt := range opts {
+		switch opt := opt.(type) {
+		case healthIntervalOption:
+			if opt > 0 {
+				m.interval = time.Duration(opt)
+			}
+		case healthHistoryOption:
+			if opt > 0 {
+				m.maxHistory = int(opt)
+			}
+		case healthListenerOption:
+			m.listeners = append(m.listeners, opt.v)
+		}
+	}
+	return m
+}
+
+// Check the API every interval until ctx is done, the first check is immediate.
+func (m *HealthMonitor) Run(ctx context.Context) {
+
+	t := time.NewTicker(m.interval)
+	defer t.Stop()
+	for {
+		m.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Check the API once and record the sample.
+func (m *HealthMonitor) Check(ctx context.Context) HealthSample {
+
+	sample := HealthSample{At: time.Now(), Health: HealthUnreachable}
+
+	start := time.Now()
+	res, err := m.client.Status(ctx)
+	sample.StatusLatency = time.Since(start)
+	if err == nil {
+		sample.Health = APIHealth(res.Status)
+		if sample.Health.Ready() {
+			start = time.Now()
+			valid, sErr := m.client.ValidateSession(ctx)
+			sample.SessionLatency = time.Since(start)
+			switch {
+			case sErr != nil:
+				err = sErr
+				sample.Health = HealthSessionUnknown
+			case !valid:
+				sample.Health = HealthUnauthenticated
+			}
+		}
+	}
+	if err != nil {
+		sample.Error = err.Error()
+	}
+
+	m.mu.Lock()
+	from := m.current
+	m.current = sample.Health
+	m.history = append(m.history, sample)
+	if len(m.history) > m.maxHistory {
+		m.history = m.history[len(m.history)-m.maxHistory:]
+	}
+	m.mu.Unlock()
+
+	if from != sample.Health {
+		for _, l := range m.listeners {
+			l(from, sample.Health)
+		}
+	}
+	return sample
+}
+
+func (m *HealthMonitor) Current() APIHealth {
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.current
+}
+
+// The recorded samples, oldest first.
+func (m *HealthMonitor) History() []HealthSample {
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]HealthSample(nil), m.history...)
+}
+
+// Share of the recorded samples where the API was ready, zero without samples.
+func (m *HealthMonitor) Availability() float64 {
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if len(m.history) == 0 {
+		return 0
+	}
+	ready := 0
+	for _, s := range m.history {
+		if s.Health.Ready() {
+			ready++
+		}
+	}
+	return float64(ready) / float64(len(m.history))
+}
+
+// Average Status latency of the recorded samples.
+func (m *HealthMonitor) AverageLatency() time.Duration {
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if len(m.history) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, s := range m.history {
+		total += s.StatusLatency
+	}
+	return total / time.Duration(len(m.history))
+}
+
+func (m *HealthMonitor) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+
+	var (
+		health = m.Current()
+		body   = struct {
+			Health         APIHealth     `json:"health"`
+			Ready          bool          `json:"ready"`
+			Availability   float64       `json:"availability"`
+			AverageLatency time.Duration `json:"averageLatency"`
+			Last           *HealthSample `json:"last,omitempty"`
+		}{
+			Health:         health,
+			Ready:          health.Ready(),
+			Availability:   m.Availability(),
+			AverageLatency: m.AverageLatency(),
+		}
+	)
+	if history := m.History(); len(history) > 0 {
+		body.Last = &history[len(history)-1]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !body.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,98 @@
+package efashevdsapigo
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const historyDateLayout = "2006-01-02"
+
+// Walk through all transactions matching the filter, pages are fetched as the sequence is consumed.
+// The walk starts at filter.Page and stops at the first error, which is yielded with an empty record.
+// It also stops at a page starting like the previous one, as when the server ignores the page requested.
+func AllTransactions(ctx context.Context, cl Client, filter TransactionFilter, opts ...Option) iter.Seq2[TransactionRecord, error] {
+
+	return func(yield func(TransactionRecord, error) bool) {
+		if filter.Page <= 0 {
+			filter.Page = 1
+		}
+		var prevFirst string
+		for {
+			res, err := cl.ListTransactions(ctx, filter, opts...)
+			if err != nil {
+				yield(TransactionRecord{}, err)
+				return
+			}
+			if len(res.Data) > 0 {
+				if res.Data[0].TransactionId == prevFirst {
+					return
+				}
+				prevFirst = res.Data[0].TransactionId
+			}
+			for _, trx := range res.Data {
+				if !yield(trx, nil) {
+					return
+				}
+			}
+			if res.lastPage(filter) {
+				return
+			}
+			filter.Page++
+		}
+	}
+}
+
+// The creation time of the transaction, false when CreatedAt cannot be parsed.
+func (t Transaction) CreatedTime() (time.Time, bool) {
+	return parseTimestamp(t.CreatedAt)
+}
+
+func (r *ListTransactionsResp) lastPage(filter TransactionFilter) bool {
+
+	if len(r.Data) == 0 {
+		return true
+	}
+	if r.Pagination.TotalPages > 0 {
+		return filter.Page >= r.Pagination.TotalPages
+	}
+	pageSize := filter.PageSize
+	if r.Pagination.PageSize > 0 {
+		pageSize = r.Pagination.PageSize
+	}
+	return pageSize > 0 && len(r.Data) < pageSize
+}
+
+func (f TransactionFilter) query() url.Values {
+
+	// The days are the API ones.
+	loc := TimestampLocation()
+	q := url.Values{}
+	if !f.From.IsZero() {
+		q.Set("fromDate", f.From.In(loc).Format(historyDateLayout))
+	}
+	if !f.To.IsZero() {
+		q.Set("toDate", f.To.In(loc).Format(historyDateLayout))
+	}
+	if f.VerticalId != "" {
+		q.Set("verticalId", f.VerticalId)
+	}
+	if f.TransactionStatusId != "" {
+		q.Set("trxStatusId", f.TransactionStatusId)
+	}
+	if f.CustomerAccountNumber != "" {
+		q.Set("customerAccountNumber", f.CustomerAccountNumber)
+	}
+	if f.BranchId != "" {
+		q.Set("branchId", f.BranchId)
+	}
+	if f.Page > 0 {
+		q.Set("page", strconv.Itoa(f.Page))
+	}
+	if f.PageSize > 0 {
+		q.Set("limit", strconv.Itoa(f.PageSize))
+	}
+	return q
+}
@@ -0,0 +1,63 @@
+package efashevdsapigo
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// Lists pages of two transactions, the page requested is ignored when ignorePage is set.
+type testHistoryClient struct {
+	Client
+	pages      int
+	ignorePage bool
+	calls      int
+}
+
+func (c *testHistoryClient) ListTransactions(_ context.Context, filter TransactionFilter, _ ...Option) (*ListTransactionsResp, error) {
+
+	c.calls++
+	page := filter.Page
+	if c.ignorePage {
+		page = 1
+	}
+	res := &ListTransactionsResp{}
+	if page > c.pages {
+		return res, nil
+	}
+	for i := range 2 {
+		var trx TransactionRecord
+		trx.TransactionId = fmt.Sprintf("trx-%d-%d", page, i)
+		res.Data = append(res.Data, trx)
+	}
+	return res, nil
+}
+
+func TestAllTransactions(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		client    *testHistoryClient
+		wantTrxs  int
+		wantCalls int
+	}{
+		// Neither the total pages nor the page size are known, so the walk goes on until an empty page.
+		{name: "paged", client: &testHistoryClient{pages: 3}, wantTrxs: 6, wantCalls: 4},
+		{name: "page ignored", client: &testHistoryClient{pages: 3, ignorePage: true}, wantTrxs: 2, wantCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			n := 0
+			for _, err := range AllTransactions(context.Background(), tt.client, TransactionFilter{}) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				n++
+			}
+			if n != tt.wantTrxs || tt.client.calls != tt.wantCalls {
+				t.Fatalf("walked %d transactions in %d calls, want %d in %d", n, tt.client.calls, tt.wantTrxs, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package efashevdsapigo
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// An API request about to be sent.
+type RequestInfo struct {
+	// The endpoint named by its path with ids replaced, e.g /vend/execute or /vend/{id}/status.
+	Endpoint      string
+	Method        string
+	TransactionId string
+	// The JSON request body with secrets and tokens redacted, empty without body.
+	Body string
+	// When the request was handed to the client, before rate limiting.
+	Time time.Time
+}
+
+// The outcome of an API call.
+type ResponseInfo struct {
+	Request RequestInfo
+	// Zero when no response was received.
+	StatusCode int
+	// The decoded response as returned by the call, e.g *VendExecuteResp. It is nil on error.
+	// The responses of Auth, RefreshToken and ElectricityTokens bear tokens, they are not redacted.
+	Response any
+	Err      error
+	// Time elapsed since the request time until the call returned.
+	Duration time.Duration
+}
+
+// Called before an API request is sent, check WithOnRequestOption.
+type RequestHook func(ctx context.Context, req RequestInfo)
+
+// Called when an API call returns, check WithOnResponseOption.
+type ResponseHook func(ctx context.Context, res ResponseInfo)
+
+// Hooks of an API call, carried by its context from startCall to do.
+type callHooks struct {
+	onRequest  []RequestHook
+	onResponse []ResponseHook
+
+	sent       bool
+	req        RequestInfo
+	statusCode int
+}
+
+type callHooksKey struct{}
+
+// The client hooks followed by the call ones, nil without hooks.
+func (c *client) callHooks(opts []Option) *callHooks {
+
+	h := &callHooks{
+		onRequest:  c.onRequest,
+		onResponse: c.onResponse,
+	}
+	for _, opt := range opts {
+		switch opt := opt.(type) {
+		case onRequestOption:
+			h.onRequest = append(h.onRequest[:len(h.onRequest):len(h.onRequest)], opt.v)
+		case onResponseOption:
+			h.onResponse = append(h.onResponse[:len(h.onResponse):len(h.onResponse)], opt.v)
+		}
+	}
+	if len(h.onRequest) == 0 && len(h.onResponse) == 0 {
+		return nil
+	}
+	return h
+}
+
+// Run the request hooks, it is called by do once the request is built.
+func (h *callHooks) request(req *http.Request, call callParams) {
+
+	h.sent = true
+	h.req = RequestInfo{
+		Endpoint: call.endpoint,
+		Method:   req.Method,
+		Body:     redactJSON(requestBody(req)),
+		Time:     time.Now(),
+	}
+	if info, ok := callInfoFrom(req.Context()); ok {
+		h.req.TransactionId = info.transactionId
+	}
+	for _, hook := range h.onRequest {
+		hook(req.Context(), h.req)
+	}
+}
+
+// Run the response hooks, calls which failed before a request was built, e.g on validation, are not reported.
+func (h *callHooks) response(ctx context.Context, res any, err error) {
+
+	if h == nil || !h.sent {
+		return
+	}
+	info := ResponseInfo{
+		Request:    h.req,
+		StatusCode: h.statusCode,
+		Response:   res,
+		Err:        err,
+		Duration:   time.Since(h.req.Time),
+	}
+	if err != nil {
+		info.Response = nil
+	}
+	for _, hook := range h.onResponse {
+		hook(ctx, info)
+	}
+}
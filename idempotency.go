@@ -0,0 +1,205 @@
+package efashevdsapigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// The transaction is being executed.
+	IdempotencyInFlightState = "in_flight"
+	// The execute call failed without telling whether the transaction was accepted.
+	IdempotencyAmbiguousState = "ambiguous"
+	// The transaction was accepted by the API.
+	IdempotencyCompletedState = "completed"
+)
+
+var (
+	ErrTransactionInFlight = errors.New("transaction is already being executed")
+	ErrAmbiguousExecution  = errors.New("transaction execution outcome is unknown")
+	ErrTransactionFailed   = errors.New("transaction failed")
+	// The API does not know the transaction or never accepted its execution.
+	errNotAccepted = errors.New("transaction not accepted")
+)
+
+// How long a transaction can stay in flight before it is resolved with VendTransactionStatus, e.g after a crash
+// between IdempotencyStore Begin and Set, check WithInFlightTimeoutOption.
+// An execute request may outlive it, a transaction still initiated upstream is then reported as ambiguous
+// rather than executed again.
+const DefaultInFlightTimeout = 2 * time.Minute
+
+type IdempotencyRecord struct {
+	TransactionId string
+	// in_flight┃ambiguous┃completed
+	State string
+	// The vend execute response of a completed transaction.
+	Resp *VendExecuteResp
+	// Whether the execute request may have been sent, false proves the attempt never reached the API.
+	Sent bool
+	// Compared by Claim, stores must keep it exactly, e.g in nanoseconds.
+	UpdatedAt time.Time
+}
+
+// IdempotencyStore records the transaction ids executed by VendExecute.
+// It must be safe for concurrent use, and shared by all processes executing the same transactions.
+type IdempotencyStore interface {
+	// Record the transaction as in flight when it is unknown,
+	// otherwise the existing record is returned with false.
+	Begin(ctx context.Context, transactionId string) (*IdempotencyRecord, bool, error)
+	Set(ctx context.Context, rec IdempotencyRecord) error
+	// Replace the record with next when it still has the state and update time of expect,
+	// otherwise false is returned and the record is left as is.
+	Claim(ctx context.Context, expect, next IdempotencyRecord) (bool, error)
+	// Forget the transaction so it can be executed again, it is called once a transaction definitely failed.
+	Delete(ctx context.Context, transactionId string) error
+}
+
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]IdempotencyRecord
+}
+
+// In memory IdempotencyStore, it only guards executions within the process.
+func NewMemoryIdempotencyStore() IdempotencyStore {
+	return &memoryIdempotencyStore{records: map[string]IdempotencyRecord{}}
+}
+
+func (s *memoryIdempotencyStore) Begin(_ context.Context, transactionId string) (*IdempotencyRecord, bool, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok := s.records[transactionId]; ok {
+		return &rec, false, nil
+	}
+	rec := IdempotencyRecord{TransactionId: transactionId, State: IdempotencyInFlightState, UpdatedAt: time.Now()}
+	s.records[transactionId] = rec
+	return &rec, true, nil
+}
+
+func (s *memoryIdempotencyStore) Set(_ context.Context, rec IdempotencyRecord) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[rec.TransactionId] = rec
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Claim(_ context.Context, expect, next IdempotencyRecord) (bool, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.records[expect.TransactionId]
+	if !ok || rec.State != expect.State || !rec.UpdatedAt.Equal(expect.UpdatedAt) {
+		return false, nil
+	}
+	s.records[next.TransactionId] = next
+	return true, nil
+}
+
+func (s *memoryIdempotencyStore) Delete(_ context.Context, transactionId string) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, transactionId)
+	return nil
+}
+
+// Execute the transaction at most once.
+// A completed transaction returns its recorded response, an ambiguous one or one in flight for longer than the
+// in flight timeout is resolved with VendTransactionStatus and is only executed again when the API never accepted it.
+// The record is claimed before every execution so that concurrent retries execute the transaction once.
+func (c *client) idempotentVendExecute(ctx context.Context, store IdempotencyStore, body VendExecuteBody, opts ...Option) (*VendExecuteResp, error) {
+
+	timeout := c.inFlightTimeout
+	for _, opt := range opts {
+		if opt, ok := opt.(inFlightTimeoutOption); ok && opt > 0 {
+			timeout = time.Duration(opt)
+		}
+	}
+
+	rec, fresh, err := store.Begin(ctx, body.TransactionId)
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		switch {
+		case rec.State == IdempotencyCompletedState:
+			return rec.Resp, nil
+		case rec.State == IdempotencyInFlightState && time.Since(rec.UpdatedAt) < timeout:
+			return nil, ErrTransactionInFlight
+		}
+		res, err := c.resolveExecution(ctx, store, body.TransactionId, rec.Sent, opts...)
+		if !errors.Is(err, errNotAccepted) {
+			return res, err
+		}
+		c.debug("[efashevdsapigo] ambiguous transaction not accepted upstream, executing it.", "trxId", body.TransactionId)
+	}
+
+	claimed, err := store.Claim(ctx, *rec, IdempotencyRecord{TransactionId: body.TransactionId, State: IdempotencyInFlightState, Sent: true, UpdatedAt: time.Now()})
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrTransactionInFlight
+	}
+
+	res, statusCode, err := c.vendExecute(ctx, body, opts...)
+	switch {
+	case err == nil:
+		return res, store.Set(ctx, IdempotencyRecord{TransactionId: body.TransactionId, State: IdempotencyCompletedState, Resp: res, Sent: true, UpdatedAt: time.Now()})
+	case isDefiniteFailure(statusCode, err):
+		// The request was not sent or the API answered, the transaction was not accepted and can be retried.
+		return nil, errors.Join(err, store.Delete(ctx, body.TransactionId))
+	}
+
+	c.debug("[efashevdsapigo] ambiguous vend execute, resolving with transaction status.", "trxId", body.TransactionId, "error", err)
+	setErr := store.Set(ctx, IdempotencyRecord{TransactionId: body.TransactionId, State: IdempotencyAmbiguousState, Sent: true, UpdatedAt: time.Now()})
+	if setErr != nil {
+		return nil, errors.Join(err, setErr)
+	}
+	res, resolveErr := c.resolveExecution(ctx, store, body.TransactionId, true, opts...)
+	switch {
+	case resolveErr == nil:
+		return res, nil
+	case errors.Is(resolveErr, errNotAccepted):
+		return nil, errors.Join(err, store.Delete(ctx, body.TransactionId))
+	case errors.Is(resolveErr, ErrTransactionFailed):
+		return nil, resolveErr
+	default:
+		return nil, fmt.Errorf("%w: %w", ErrAmbiguousExecution, err)
+	}
+}
+
+// Check with VendTransactionStatus whether the API accepted an ambiguous transaction.
+// Successful and pending transactions are recorded as completed. Failed and timed out ones are deleted from the store
+// and ErrTransactionFailed is returned. errNotAccepted is returned for transactions unknown upstream, and for initiated
+// ones whose execute request was never sent, as the execution of a sent one may still be under way.
+func (c *client) resolveExecution(ctx context.Context, store IdempotencyStore, transactionId string, sent bool, opts ...Option) (*VendExecuteResp, error) {
+
+	status, err := c.VendTransactionStatus(ctx, transactionId, opts...)
+	if errors.Is(err, ErrTransactionNotFound) {
+		return nil, errNotAccepted
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAmbiguousExecution, err)
+	}
+
+	switch state := status.Data.TransactionStatusId; state {
+	case TransactionSuccessedState, TransactionPendingState:
+		res := &VendExecuteResp{}
+		res.Data.PollEndpoint = fmt.Sprintf("/vend/%s/status", transactionId)
+		return res, store.Set(ctx, IdempotencyRecord{TransactionId: transactionId, State: IdempotencyCompletedState, Resp: res, Sent: true, UpdatedAt: time.Now()})
+	case TransactionFailedState, TransactionTimeoutState:
+		return nil, errors.Join(fmt.Errorf("%w: transaction %s", ErrTransactionFailed, state), store.Delete(ctx, transactionId))
+	case TransactionInitiatedtate:
+		if !sent {
+			return nil, errNotAccepted
+		}
+		return nil, fmt.Errorf("%w: transaction still initiated", ErrAmbiguousExecution)
+	default:
+		return nil, fmt.Errorf("%w: unknown transaction state %q", ErrAmbiguousExecution, state)
+	}
+}
@@ -0,0 +1,238 @@
+package efashevdsapigo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testExecuteBody = VendExecuteBody{
+	SharedVendInfo:   SharedVendInfo{VerticalId: AirtimeVerticalId, CustomerAccountNumber: "0780000000"},
+	Amount:           100,
+	TransactionId:    "trx-1",
+	DeliveryMethodId: PrintDeliveryMethodId,
+}
+
+func assertNoRecord(t *testing.T, store IdempotencyStore) {
+
+	t.Helper()
+	rec, fresh, err := store.Begin(context.Background(), testExecuteBody.TransactionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fresh {
+		t.Fatalf("transaction is still recorded as %s", rec.State)
+	}
+}
+
+func TestIdempotentVendExecuteResolution(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		trxState string
+		wantErr  error
+		// Whether the transaction stays recorded as completed.
+		completed bool
+		// Whether the transaction stays recorded as ambiguous.
+		ambiguous bool
+	}{
+		{name: "successful", trxState: TransactionSuccessedState, completed: true},
+		{name: "pending", trxState: TransactionPendingState, completed: true},
+		{name: "failed", trxState: TransactionFailedState, wantErr: ErrTransactionFailed},
+		{name: "timedout", trxState: TransactionTimeoutState, wantErr: ErrTransactionFailed},
+		// The execute request was sent and may still be processed.
+		{name: "initiated", trxState: TransactionInitiatedtate, ambiguous: true},
+		{name: "unknown", trxState: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			api := &testAPI{executeStatus: http.StatusInternalServerError, trxState: tt.trxState}
+			store := NewMemoryIdempotencyStore()
+			c := newTestClient(t, api, WithIdempotencyStoreOption(store))
+
+			res, err := c.VendExecute(context.Background(), testExecuteBody)
+			if api.statuses.Load() != 1 {
+				t.Fatalf("status looked up %d times, want 1", api.statuses.Load())
+			}
+			if tt.completed {
+				if err != nil || res == nil {
+					t.Fatalf("VendExecute() = %v, %v, want a response", res, err)
+				}
+				rec, fresh, _ := store.Begin(context.Background(), testExecuteBody.TransactionId)
+				if fresh || rec.State != IdempotencyCompletedState {
+					t.Fatalf("transaction recorded as %s, want %s", rec.State, IdempotencyCompletedState)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("VendExecute() succeeded, want an error")
+			}
+			if tt.ambiguous {
+				if !errors.Is(err, ErrAmbiguousExecution) {
+					t.Fatalf("VendExecute() error = %v, want %v", err, ErrAmbiguousExecution)
+				}
+				rec, fresh, _ := store.Begin(context.Background(), testExecuteBody.TransactionId)
+				if fresh || rec.State != IdempotencyAmbiguousState {
+					t.Fatalf("transaction recorded as %s, want %s", rec.State, IdempotencyAmbiguousState)
+				}
+				return
+			}
+			if errors.Is(err, ErrAmbiguousExecution) {
+				t.Fatalf("VendExecute() error = %v, want a definite failure", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VendExecute() error = %v, want %v", err, tt.wantErr)
+			}
+			assertNoRecord(t, store)
+		})
+	}
+}
+
+func TestIdempotentVendExecuteNotSent(t *testing.T) {
+
+	api := &testAPI{executeStatus: http.StatusOK, trxState: TransactionSuccessedState}
+	store := NewMemoryIdempotencyStore()
+	limiter := NewRateLimiter(Limit{}, map[string]Limit{"/vend/execute": {Rate: 1, Burst: 1}})
+	limiter.Backoff("/vend/execute", time.Hour)
+	c := newTestClient(t, api, WithIdempotencyStoreOption(store), WithRateLimiterOption(limiter))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := c.VendExecute(ctx, testExecuteBody)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("VendExecute() error = %v, want %v", err, ErrRateLimited)
+	}
+	if n := api.executes.Load() + api.statuses.Load(); n != 0 {
+		t.Fatalf("API called %d times, want none", n)
+	}
+	assertNoRecord(t, store)
+}
+
+func TestIdempotentVendExecuteTooManyRequests(t *testing.T) {
+
+	api := &testAPI{executeStatus: http.StatusTooManyRequests, trxState: TransactionSuccessedState}
+	store := NewMemoryIdempotencyStore()
+	c := newTestClient(t, api, WithIdempotencyStoreOption(store))
+
+	_, err := c.VendExecute(context.Background(), testExecuteBody)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("VendExecute() error = %v, want %v", err, ErrRateLimited)
+	}
+	if n := api.statuses.Load(); n != 0 {
+		t.Fatalf("status looked up %d times, want none", n)
+	}
+	assertNoRecord(t, store)
+}
+
+func TestIdempotentVendExecuteInFlight(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		age      time.Duration
+		sent     bool
+		trxState string
+		wantErr  error
+	}{
+		{name: "recent", age: time.Second, wantErr: ErrTransactionInFlight},
+		{name: "stale", age: time.Hour},
+		{name: "stale never sent and initiated", age: time.Hour, trxState: TransactionInitiatedtate},
+		{name: "stale sent and initiated", age: time.Hour, sent: true, trxState: TransactionInitiatedtate, wantErr: ErrAmbiguousExecution},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			api := &testAPI{executeStatus: http.StatusOK, trxState: tt.trxState}
+			store := NewMemoryIdempotencyStore()
+			err := store.Set(context.Background(), IdempotencyRecord{
+				TransactionId: testExecuteBody.TransactionId,
+				State:         IdempotencyInFlightState,
+				Sent:          tt.sent,
+				UpdatedAt:     time.Now().Add(-tt.age),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := newTestClient(t, api, WithIdempotencyStoreOption(store), WithInFlightTimeoutOption(time.Minute))
+
+			_, err = c.VendExecute(context.Background(), testExecuteBody)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VendExecute() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if api.executes.Load() != 0 {
+					t.Fatalf("executed %d times, want none", api.executes.Load())
+				}
+				return
+			}
+			// The stale transaction was never accepted upstream, so it is executed.
+			if api.statuses.Load() != 1 || api.executes.Load() != 1 {
+				t.Fatalf("status looked up %d times and executed %d times, want once each", api.statuses.Load(), api.executes.Load())
+			}
+		})
+	}
+}
+
+func TestIdempotentVendExecuteConcurrentRetries(t *testing.T) {
+
+	api := &testAPI{executeStatus: http.StatusOK, executeDelay: 50 * time.Millisecond}
+	store := NewMemoryIdempotencyStore()
+	err := store.Set(context.Background(), IdempotencyRecord{
+		TransactionId: testExecuteBody.TransactionId,
+		State:         IdempotencyAmbiguousState,
+		Sent:          true,
+		UpdatedAt:     time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newTestClient(t, api, WithIdempotencyStoreOption(store))
+
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.VendExecute(context.Background(), testExecuteBody)
+			if err != nil && !errors.Is(err, ErrTransactionInFlight) {
+				t.Errorf("VendExecute() error = %v, want nil or %v", err, ErrTransactionInFlight)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := api.executes.Load(); n != 1 {
+		t.Fatalf("executed %d times, want 1", n)
+	}
+}
+
+func TestVendExecuteNotSentIsDefinite(t *testing.T) {
+
+	api := &testAPI{executeStatus: http.StatusOK}
+	journal, err := NewFileJournal(filepath.Join(t.TempDir(), "journal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+	breaker := NewCircuitBreaker(WithBreakerThresholdOption(1))
+	c := newTestClient(t, api, WithCircuitBreakerOption(breaker), WithJournalOption(journal))
+	breaker.record(true)
+
+	_, err = c.VendExecute(context.Background(), testExecuteBody)
+	if !errors.Is(err, ErrAPIDown) {
+		t.Fatalf("VendExecute() error = %v, want %v", err, ErrAPIDown)
+	}
+	if api.executes.Load() != 0 {
+		t.Fatalf("executed %d times, want none", api.executes.Load())
+	}
+	pending, err := journal.Pending(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("journal has %d pending transactions, want none", len(pending))
+	}
+}
@@ -0,0 +1,252 @@
+package efashevdsapigo
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	JournalValidateStep = "validate"
+	JournalExecuteStep  = "execute"
+	JournalStatusStep   = "status"
+
+	DefaultPollInterval        = 5 * time.Second
+	DefaultRecoveryConcurrency = 8
+)
+
+// A step of a vend transaction recorded in a Journal.
+type JournalEntry struct {
+	TransactionId string `json:"trxId"`
+	// validate┃execute┃status
+	Step string `json:"step"`
+	// The transaction state known after the step, empty after validate.
+	// An execute step is recorded as initiated before the request is sent.
+	State                 string    `json:"state,omitempty"`
+	VerticalId            string    `json:"verticalId,omitempty"`
+	CustomerAccountNumber string    `json:"customerAccountNumber,omitempty"`
+	Amount                float64   `json:"amount,omitempty"`
+	Error                 string    `json:"error,omitempty"`
+	At                    time.Time `json:"at"`
+}
+
+// Whether the transaction can still change state upstream, i.e it was executed without reaching a final state.
+func (e JournalEntry) Pending() bool {
+	return e.Step != JournalValidateStep && !IsTerminalState(e.State)
+}
+
+// Journal durably records vend transaction steps so they can be recovered after a crash, check Recover.
+// It must be safe for concurrent use.
+type Journal interface {
+	Record(ctx context.Context, entry JournalEntry) error
+	// The latest entry of every pending transaction.
+	Pending(ctx context.Context) ([]JournalEntry, error)
+}
+
+// Whether a transaction state is final.
+func IsTerminalState(state string) bool {
+
+	switch state {
+	case TransactionSuccessedState, TransactionFailedState, TransactionTimeoutState:
+		return true
+	}
+	return false
+}
+
+// FileJournal is a Journal appending entries as JSON lines to a file, every entry is synced to disk before returning.
+type FileJournal struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFileJournal(path string) (*FileJournal, error) {
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileJournal{file: f}, nil
+}
+
+func (j *FileJournal) Record(_ context.Context, entry JournalEntry) error {
+
+	if entry.At.IsZero() {
+		entry.At = time.Now()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_, err = j.file.Write(append(line, '\n'))
+	if err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+func (j *FileJournal) Pending(_ context.Context) ([]JournalEntry, error) {
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	_, err := j.file.Seek(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		latest = map[string]JournalEntry{}
+		order  []string
+		sc     = bufio.NewScanner(j.file)
+	)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var e JournalEntry
+		// A torn last line is expected after a crash.
+		if json.Unmarshal(sc.Bytes(), &e) != nil {
+			continue
+		}
+		if _, ok := latest[e.TransactionId]; !ok {
+			order = append(order, e.TransactionId)
+		}
+		latest[e.TransactionId] = e
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	var res []JournalEntry
+	for _, id := range order {
+		if e := latest[id]; e.Pending() {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+func (j *FileJournal) Close() error {
+	return j.file.Close()
+}
+
+// Final state of a transaction resumed by Recover.
+type RecoveryResult struct {
+	TransactionId string
+	State         string
+	// The last status response, nil when the transaction is unknown upstream.
+	Status *VendTransactionStatusResp
+	// Set when the transaction state could not be resolved.
+	Err error
+}
+
+// Poll the status of every pending transaction of the journal until it reaches a final state, it is meant to run on startup.
+// Transactions unknown upstream were never executed and are recorded as failed.
+// At most DefaultRecoveryConcurrency transactions are polled at once, check WithRecoveryConcurrencyOption.
+// Polling stops once ctx is done, the unresolved transactions are reported with the context error.
+func Recover(ctx context.Context, cl Client, journal Journal, opts ...Option) ([]RecoveryResult, error) {
+
+	var (
+		interval    = DefaultPollInterval
+		concurrency = DefaultRecoveryConcurrency
+	)
+	for _, opt := range opts {
+		switch opt := opt.(type) {
+		case pollIntervalOption:
+			if opt > 0 {
+				interval = time.Duration(opt)
+			}
+		case recoveryConcurrencyOption:
+			if opt > 0 {
+				concurrency = int(opt)
+			}
+		}
+	}
+	opts = append(opts, WithJournalOption(journal))
+
+	pending, err := journal.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, concurrency)
+		res = make([]RecoveryResult, len(pending))
+	)
+	for i, e := range pending {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			res[i] = RecoveryResult{TransactionId: e.TransactionId, State: e.State, Err: ctx.Err()}
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			res[i] = recoverTransaction(ctx, cl, journal, e, interval, opts...)
+		}()
+	}
+	wg.Wait()
+	return res, nil
+}
+
+func recoverTransaction(ctx context.Context, cl Client, journal Journal, entry JournalEntry, interval time.Duration, opts ...Option) RecoveryResult {
+
+	res := RecoveryResult{TransactionId: entry.TransactionId, State: entry.State}
+	for {
+		status, err := cl.VendTransactionStatus(ctx, entry.TransactionId, opts...)
+		switch {
+		case errors.Is(err, ErrTransactionNotFound):
+			entry.Step, entry.State, entry.Error, entry.At = JournalStatusStep, TransactionFailedState, err.Error(), time.Now()
+			res.State, res.Err = entry.State, journal.Record(ctx, entry)
+			return res
+		case err == nil:
+			res.Status, res.State = status, status.Data.TransactionStatusId
+			if IsTerminalState(res.State) {
+				return res
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			res.Err = errors.Join(ctx.Err(), err)
+			return res
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Record the entry in the journal attached to the client or the call, failures are only reported to the debugger.
+func (c *client) record(ctx context.Context, entry JournalEntry, opts ...Option) {
+
+	journal := c.journalFor(opts...)
+	if journal == nil {
+		return
+	}
+	if entry.At.IsZero() {
+		entry.At = time.Now()
+	}
+	err := journal.Record(ctx, entry)
+	if err != nil {
+		c.debug("[efashevdsapigo] journal record failed.", "trxId", entry.TransactionId, "step", entry.Step, "error", err)
+	}
+}
+
+func (c *client) journalFor(opts ...Option) Journal {
+
+	journal := c.journal
+	for _, opt := range opts {
+		if opt, ok := opt.(journalOption); ok {
+			journal = opt.v
+		}
+	}
+	return journal
+}
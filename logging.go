@@ -0,0 +1,119 @@
+package efashevdsapigo
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Default levels of the request logs, check WithLogLevelsOption.
+const (
+	DefaultRequestLogLevel = slog.LevelDebug
+	DefaultFailureLogLevel = slog.LevelWarn
+)
+
+const redacted = "[REDACTED]"
+
+// Lower cased JSON keys and headers whose values are never logged.
+var redactedKeys = map[string]bool{
+	"api_secret":    true,
+	"authorization": true,
+	"accesstoken":   true,
+	"refreshtoken":  true,
+	"voucher":       true,
+	// electricity tokens
+	"token":  true,
+	"token2": true,
+	"token3": true,
+}
+
+type logConfig struct {
+	logger       *slog.Logger
+	requestLevel slog.Level
+	failureLevel slog.Level
+	bodies       bool
+}
+
+// Log a request sent by do, failures are requests which errored or were answered with a status of 400 and above.
+func (l *logConfig) logRequest(req *http.Request, call callParams, statusCode int, d time.Duration, err error, reqBody, resBody []byte) {
+
+	ctx := req.Context()
+	level := l.requestLevel
+	if err != nil || statusCode >= http.StatusBadRequest {
+		level = l.failureLevel
+	}
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("endpoint", call.endpoint),
+		slog.String("method", req.Method),
+		slog.Int("status", statusCode),
+		slog.Duration("duration", d),
+	}
+	if info, ok := callInfoFrom(ctx); ok && info.transactionId != "" {
+		attrs = append(attrs, slog.String("trxId", info.transactionId))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	if l.bodies {
+		attrs = append(attrs,
+			slog.Any("requestHeaders", redactHeader(req.Header)),
+			slog.String("requestBody", redactJSON(reqBody)),
+			slog.String("responseBody", redactJSON(resBody)),
+		)
+	}
+	l.logger.LogAttrs(ctx, level, "[efashevdsapigo] request", attrs...)
+}
+
+func redactHeader(h http.Header) http.Header {
+
+	res := h.Clone()
+	for k := range res {
+		if redactedKeys[strings.ToLower(k)] {
+			res[k] = []string{redacted}
+		}
+	}
+	return res
+}
+
+// The JSON body with the values of redacted keys replaced, bodies which are not JSON are not logged as they cannot be redacted.
+func redactJSON(raw []byte) string {
+
+	if len(raw) == 0 {
+		return ""
+	}
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return fmt.Sprintf("[%d bytes, not JSON]", len(raw))
+	}
+	res, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return fmt.Sprintf("[%d bytes]", len(raw))
+	}
+	return string(res)
+}
+
+func redactValue(v any) any {
+
+	switch v := v.(type) {
+	case map[string]any:
+		for k, val := range v {
+			if redactedKeys[strings.ToLower(k)] {
+				v[k] = redacted
+				continue
+			}
+			v[k] = redactValue(val)
+		}
+	case []any:
+		for i, val := range v {
+			v[i] = redactValue(val)
+		}
+	}
+	return v
+}
@@ -1,8 +1,13 @@
 package efashevdsapigo
 
 import (
+	"log/slog"
 	"net/http"
 	"net/url"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type baseUrlOption struct {
@@ -69,3 +74,261 @@ func (opt debugOption) value() any { return opt.v }
 func WithDebuggerOption(debugger Debugger) Option {
 	return debugOption{v: debugger}
 }
+
+type catalogTTLOption time.Duration
+
+func (opt catalogTTLOption) value() any { return opt }
+
+// Set how long a fetched verticals list is considered fresh by a Catalog.
+func WithCatalogTTLOption(ttl time.Duration) Option {
+	return catalogTTLOption(ttl)
+}
+
+type catalogStaleOption time.Duration
+
+func (opt catalogStaleOption) value() any { return opt }
+
+// Set how long after the TTL a Catalog keeps serving the expired list while revalidating it in background.
+// once this window has passed, lookups block until the list is fetched again.
+func WithCatalogStaleOption(stale time.Duration) Option {
+	return catalogStaleOption(stale)
+}
+
+type catalogListenerOption struct {
+	v func(CatalogEvent)
+}
+
+func (opt catalogListenerOption) value() any { return opt.v }
+
+// Attach a listener notified when a Catalog detects added, removed or deactivated verticals.
+func WithCatalogListenerOption(listener func(CatalogEvent)) Option {
+	return catalogListenerOption{v: listener}
+}
+
+type idempotencyStoreOption struct {
+	v IdempotencyStore
+}
+
+func (opt idempotencyStoreOption) value() any { return opt.v }
+
+// Guard VendExecute against executing a transaction id more than once, check IdempotencyStore.
+// It can be attached during creation of a client or to a single VendExecute call.
+func WithIdempotencyStoreOption(store IdempotencyStore) Option {
+	return idempotencyStoreOption{v: store}
+}
+
+type journalOption struct {
+	v Journal
+}
+
+func (opt journalOption) value() any { return opt.v }
+
+// Record vend validate, execute and status steps in a journal, check Recover.
+// It can be attached during creation of a client or to a single call.
+func WithJournalOption(journal Journal) Option {
+	return journalOption{v: journal}
+}
+
+type pollIntervalOption time.Duration
+
+func (opt pollIntervalOption) value() any { return opt }
+
+// Interval between transaction status polls, non-positive intervals are ignored.
+func WithPollIntervalOption(interval time.Duration) Option {
+	return pollIntervalOption(interval)
+}
+
+type recoveryConcurrencyOption int
+
+func (opt recoveryConcurrencyOption) value() any { return opt }
+
+// Maximum number of transactions polled at once by Recover, DefaultRecoveryConcurrency is used by default.
+func WithRecoveryConcurrencyOption(n int) Option {
+	return recoveryConcurrencyOption(n)
+}
+
+type rateLimiterOption struct {
+	v *RateLimiter
+}
+
+func (opt rateLimiterOption) value() any { return opt.v }
+
+// Throttle requests per endpoint with the limiter, it also pauses endpoints answering 429 for their Retry-After.
+// It can be attached during creation of a client or to a single call.
+func WithRateLimiterOption(limiter *RateLimiter) Option {
+	return rateLimiterOption{v: limiter}
+}
+
+type circuitBreakerOption struct {
+	v *CircuitBreaker
+}
+
+func (opt circuitBreakerOption) value() any { return opt.v }
+
+// Short-circuit calls with ErrAPIDown while the breaker is open, it is only attached during creation of a client.
+func WithCircuitBreakerOption(breaker *CircuitBreaker) Option {
+	return circuitBreakerOption{v: breaker}
+}
+
+type breakerThresholdOption int
+
+func (opt breakerThresholdOption) value() any { return opt }
+
+// Number of consecutive server errors or timeouts opening a CircuitBreaker.
+func WithBreakerThresholdOption(threshold int) Option {
+	return breakerThresholdOption(threshold)
+}
+
+type breakerProbeIntervalOption time.Duration
+
+func (opt breakerProbeIntervalOption) value() any { return opt }
+
+// Interval between the Status probes of an open CircuitBreaker, it also bounds each probe.
+// Non-positive intervals are ignored.
+func WithBreakerProbeIntervalOption(interval time.Duration) Option {
+	return breakerProbeIntervalOption(interval)
+}
+
+type breakerListenerOption struct {
+	v func(from, to BreakerState)
+}
+
+func (opt breakerListenerOption) value() any { return opt.v }
+
+// Attach a listener notified on every CircuitBreaker state change.
+func WithBreakerListenerOption(listener func(from, to BreakerState)) Option {
+	return breakerListenerOption{v: listener}
+}
+
+type healthIntervalOption time.Duration
+
+func (opt healthIntervalOption) value() any { return opt }
+
+// Interval between the checks of a HealthMonitor.
+func WithHealthIntervalOption(interval time.Duration) Option {
+	return healthIntervalOption(interval)
+}
+
+type healthHistoryOption int
+
+func (opt healthHistoryOption) value() any { return opt }
+
+// Number of samples a HealthMonitor keeps.
+func WithHealthHistoryOption(size int) Option {
+	return healthHistoryOption(size)
+}
+
+type healthListenerOption struct {
+	v func(from, to APIHealth)
+}
+
+func (opt healthListenerOption) value() any { return opt.v }
+
+// Attach a listener notified when a HealthMonitor observes a health change.
+func WithHealthListenerOption(listener func(from, to APIHealth)) Option {
+	return healthListenerOption{v: listener}
+}
+
+type tracerProviderOption struct {
+	v trace.TracerProvider
+}
+
+func (opt tracerProviderOption) value() any { return opt.v }
+
+// Enable OpenTelemetry tracing with a span per API call, only during creation of a client.
+// When only metrics are enabled, the global tracer provider is used.
+func WithTracerProviderOption(tp trace.TracerProvider) Option {
+	return tracerProviderOption{v: tp}
+}
+
+type meterProviderOption struct {
+	v metric.MeterProvider
+}
+
+func (opt meterProviderOption) value() any { return opt.v }
+
+// Enable OpenTelemetry metrics for request latency, errors, token refreshes and vend outcomes, only during creation of a client.
+// When only tracing is enabled, the global meter provider is used.
+func WithMeterProviderOption(mp metric.MeterProvider) Option {
+	return meterProviderOption{v: mp}
+}
+
+type loggerOption struct {
+	v *slog.Logger
+}
+
+func (opt loggerOption) value() any { return opt.v }
+
+// Log every request with its endpoint, method, status, duration and trxId, only during creation of a client.
+// Debug messages are also logged to it at debug level.
+func WithLoggerOption(logger *slog.Logger) Option {
+	return loggerOption{v: logger}
+}
+
+type logLevelsOption struct {
+	request slog.Level
+	failure slog.Level
+}
+
+func (opt logLevelsOption) value() any { return opt }
+
+// Set the levels of the request logs, only during creation of a client.
+// Failures are requests which errored or were answered with a status of 400 and above, check DefaultRequestLogLevel and DefaultFailureLogLevel.
+func WithLogLevelsOption(request, failure slog.Level) Option {
+	return logLevelsOption{request: request, failure: failure}
+}
+
+type logBodiesOption bool
+
+func (opt logBodiesOption) value() any { return opt }
+
+// Log the request headers and the request and response bodies, redacted, only during creation of a client.
+func WithLogBodiesOption(enabled bool) Option {
+	return logBodiesOption(enabled)
+}
+
+type onRequestOption struct {
+	v RequestHook
+}
+
+func (opt onRequestOption) value() any { return opt.v }
+
+// Call the hook before every API request is sent, e.g to write audit records.
+// It applies to all calls during creation of a client or to a single call, client hooks run first.
+func WithOnRequestOption(hook RequestHook) Option {
+	return onRequestOption{v: hook}
+}
+
+type onResponseOption struct {
+	v ResponseHook
+}
+
+func (opt onResponseOption) value() any { return opt.v }
+
+// Call the hook when every API call returns with its decoded response or error and its duration.
+// It applies to all calls during creation of a client or to a single call, client hooks run first.
+func WithOnResponseOption(hook ResponseHook) Option {
+	return onResponseOption{v: hook}
+}
+
+type inFlightTimeoutOption time.Duration
+
+func (opt inFlightTimeoutOption) value() any { return opt }
+
+// Set how long a transaction recorded in flight by the IdempotencyStore blocks executions of the same id,
+// older in flight transactions are resolved with VendTransactionStatus. Check DefaultInFlightTimeout.
+// It can be attached during creation of a client or to a single VendExecute call.
+func WithInFlightTimeoutOption(d time.Duration) Option {
+	return inFlightTimeoutOption(d)
+}
+
+type accountNumberValidationOption bool
+
+func (opt accountNumberValidationOption) value() any { return opt }
+
+// Check and normalize the customer account number with the validator registered for the vertical before VendValidate
+// and VendExecute send it, check RegisterAccountNumberValidator. By default the account number is only required to be set.
+// It can be attached during creation of a client or to a single VendValidate or VendExecute call.
+func WithAccountNumberValidationOption(enabled bool) Option {
+	return accountNumberValidationOption(enabled)
+}
@@ -0,0 +1,177 @@
+package efashevdsapigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// API credentials of an agency branch.
+type Credentials struct {
+	// The branch the credentials belong to, when empty it is taken from the auth response.
+	BranchId  string
+	ApiKey    string
+	ApiSecret string
+}
+
+// Pool holds an authenticated client per agency branch.
+// Its clients share one HTTP client, thus one transport and connection pool, unless a custom client option is given.
+type Pool struct {
+	mu      sync.RWMutex
+	clients map[string]Client
+	opts    []Option
+}
+
+func NewPool(ctx context.Context, creds []Credentials, opts ...Option) (*Pool, error) {
+
+	custom := false
+	for _, opt := range opts {
+		if _, ok := opt.(customClientOption); ok {
+			custom = true
+		}
+	}
+	if !custom {
+		// A replaced default transport, e.g by instrumentation, is shared as is.
+		transport := http.DefaultTransport
+		if t, ok := transport.(*http.Transport); ok {
+			transport = t.Clone()
+		}
+		opts = append(opts, WithCustomClientOption(&http.Client{Transport: transport}))
+	}
+
+	p := &Pool{clients: map[string]Client{}, opts: opts}
+	for _, cred := range creds {
+		err := p.Add(ctx, cred)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Authenticate and add the client of a branch, it replaces the branch previous client.
+func (p *Pool) Add(ctx context.Context, cred Credentials) error {
+
+	cl, err := NewClient(ctx, cred.ApiKey, cred.ApiSecret, p.opts...)
+	if err != nil {
+		return err
+	}
+	branchId := cred.BranchId
+	if branchId == "" {
+		branchId = cl.Profile().AgencyBranch.BranchId
+	}
+	if branchId == "" {
+		return ValidationError("branch id not provided nor returned by auth")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clients[branchId] = cl
+	return nil
+}
+
+func (p *Pool) Remove(branchId string) {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.clients, branchId)
+}
+
+// The client of a branch, ErrBranchNotFound is returned for unknown branches.
+func (p *Pool) Client(branchId string) (Client, error) {
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	cl, ok := p.clients[branchId]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrBranchNotFound, branchId)
+	}
+	return cl, nil
+}
+
+// The branches id sorted.
+func (p *Pool) Branches() []string {
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	res := make([]string, 0, len(p.clients))
+	for id := range p.clients {
+		res = append(res, id)
+	}
+	slices.Sort(res)
+	return res
+}
+
+// Call fn concurrently with every branch client, errors are joined and prefixed by their branch id.
+func (p *Pool) Each(ctx context.Context, fn func(ctx context.Context, branchId string, cl Client) error) error {
+
+	p.mu.RLock()
+	clients := make(map[string]Client, len(p.clients))
+	for id, cl := range p.clients {
+		clients[id] = cl
+	}
+	p.mu.RUnlock()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for id, cl := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := fn(ctx, id, cl)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("branch %s: %w", id, err))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Renew the tokens of every branch client which are expired.
+func (p *Pool) InitAuth(ctx context.Context, opts ...Option) error {
+
+	return p.Each(ctx, func(ctx context.Context, _ string, cl Client) error {
+		return cl.InitAuth(ctx, opts...)
+	})
+}
+
+type PoolBalance struct {
+	// Balances per branch id.
+	Branches map[string]*BalanceResp
+	// Sum of the balances with the same id across branches.
+	Totals map[string]float64
+	Total  float64
+}
+
+// Balances of every branch and their totals, the balances fetched are returned along the error of the failed branches.
+func (p *Pool) Balances(ctx context.Context, opts ...Option) (*PoolBalance, error) {
+
+	var (
+		mu  sync.Mutex
+		res = &PoolBalance{Branches: map[string]*BalanceResp{}, Totals: map[string]float64{}}
+	)
+	err := p.Each(ctx, func(ctx context.Context, branchId string, cl Client) error {
+		b, err := cl.Balance(ctx, opts...)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		res.Branches[branchId] = b
+		for _, v := range b.Data {
+			res.Totals[v.Id] += v.Balance
+		}
+		res.Total += b.Total
+		return nil
+	})
+	return res, err
+}
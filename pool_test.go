@@ -0,0 +1,28 @@
+package efashevdsapigo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestNewPoolReplacedDefaultTransport(t *testing.T) {
+
+	defaultTransport := http.DefaultTransport
+	http.DefaultTransport = roundTripperFunc(defaultTransport.RoundTrip)
+	defer func() { http.DefaultTransport = defaultTransport }()
+
+	p, err := NewPool(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if branches := p.Branches(); len(branches) != 0 {
+		t.Fatalf("Branches() = %v, want none", branches)
+	}
+}
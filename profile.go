@@ -0,0 +1,40 @@
+package efashevdsapigo
+
+import "strings"
+
+// Check the agency and branch statuses allow vending, a typed error such as ErrAgencySuspended
+// or ErrKYCPending is returned when they do not.
+// Only the documented blocking statuses prevent vending, statuses are compared case insensitively
+// and unknown ones are left to the API to enforce.
+func (p Profile) CanVend() error {
+
+	switch strings.ToLower(strings.TrimSpace(p.AgencyAccount.AgencyStatusId)) {
+	case AgencyInactiveStatus:
+		return ErrAgencyInactive
+	case AgencySuspendedStatus:
+		return ErrAgencySuspended
+	case AgencyBlacklistedStatus:
+		return ErrAgencyBlacklisted
+	case AgencyKYCPendingStatus:
+		return ErrKYCPending
+	}
+	switch strings.ToLower(strings.TrimSpace(p.AgencyBranch.BranchStatusId)) {
+	case AgencyInactiveStatus, AgencySuspendedStatus, AgencyBlacklistedStatus:
+		return ErrBranchInactive
+	}
+	return nil
+}
+
+func (c *client) Profile() Profile {
+
+	c.profileMu.RLock()
+	defer c.profileMu.RUnlock()
+	return c.profile
+}
+
+func (c *client) setProfile(res *AuthResp) {
+
+	c.profileMu.Lock()
+	defer c.profileMu.Unlock()
+	c.profile = Profile{AgencyAccount: res.Data.AgencyAccount, AgencyBranch: res.Data.AgencyBranch}
+}
@@ -0,0 +1,188 @@
+package efashevdsapigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var ErrRateLimited = errors.New("rate limited")
+
+// RateLimitError is returned when a request is throttled, either by the API with 429 or
+// because the local limiter cannot serve it before the context deadline.
+type RateLimitError struct {
+	Endpoint string
+	// How long to wait before retrying, zero when unknown.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s %s, retry after %s", e.Endpoint, ErrRateLimited, e.RetryAfter)
+	}
+	return fmt.Sprintf("%s %s", e.Endpoint, ErrRateLimited)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// Token bucket limit, Rate tokens are added per second up to Burst.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimiter is a token bucket rate limiter per endpoint, check WithRateLimiterOption.
+// Endpoints are named by their path with ids replaced, e.g /vend/execute, /auth or /vend/{id}/status.
+type RateLimiter struct {
+	def    Limit
+	limits map[string]Limit
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	limit  Limit
+	tokens float64
+	last   time.Time
+	// Set from the Retry-After of a 429, no token is served before it.
+	pausedUntil time.Time
+}
+
+// The default limit applies to endpoints without their own limit, a zero default leaves them unlimited.
+func NewRateLimiter(def Limit, perEndpoint map[string]Limit) *RateLimiter {
+
+	return &RateLimiter{
+		def:     def,
+		limits:  perEndpoint,
+		buckets: map[string]*bucket{},
+	}
+}
+
+// Block until the endpoint can be called. It fails right away with a *RateLimitError when the wait
+// would exceed the context deadline, or with the context error if it is done while waiting.
+func (l *RateLimiter) Wait(ctx context.Context, endpoint string) error {
+
+	delay, ok := l.reserve(ctx, endpoint)
+	if !ok {
+		return &RateLimitError{Endpoint: endpoint, RetryAfter: delay}
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel(endpoint)
+		return ctx.Err()
+	}
+}
+
+// Pause the endpoint for the duration, it is called when the API answers 429.
+// Unlimited endpoints get a bucket which only holds the pause.
+func (l *RateLimiter) Backoff(endpoint string, d time.Duration) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.bucket(endpoint)
+	if b == nil {
+		b = &bucket{last: time.Now()}
+		l.buckets[endpoint] = b
+	}
+	if until := time.Now().Add(d); until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+	b.tokens = 0
+}
+
+// Take a token and return how long to wait for it, false is returned without taking it when the wait exceeds the context deadline.
+func (l *RateLimiter) reserve(ctx context.Context, endpoint string) (time.Duration, bool) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.bucket(endpoint)
+	if b == nil {
+		return 0, true
+	}
+	var (
+		now     = time.Now()
+		limited = b.limit.Rate > 0
+		delay   time.Duration
+	)
+	if limited {
+		b.tokens = min(float64(b.limit.Burst), b.tokens+now.Sub(b.last).Seconds()*b.limit.Rate)
+		b.last = now
+		if b.tokens < 1 {
+			delay = time.Duration((1 - b.tokens) / b.limit.Rate * float64(time.Second))
+		}
+	}
+	if pause := b.pausedUntil.Sub(now); pause > delay {
+		delay = pause
+	}
+	if deadline, ok := ctx.Deadline(); ok && now.Add(delay).After(deadline) {
+		return delay, false
+	}
+	if limited {
+		b.tokens--
+	}
+	return delay, true
+}
+
+// Give back a token taken by a wait which was canceled.
+func (l *RateLimiter) cancel(endpoint string) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if b := l.bucket(endpoint); b != nil && b.limit.Rate > 0 {
+		b.tokens = min(float64(b.limit.Burst), b.tokens+1)
+	}
+}
+
+// The endpoint bucket, nil when the endpoint is unlimited and was never paused.
+func (l *RateLimiter) bucket(endpoint string) *bucket {
+
+	if b, ok := l.buckets[endpoint]; ok {
+		return b
+	}
+	limit, ok := l.limits[endpoint]
+	if !ok {
+		limit = l.def
+	}
+	if limit.Rate <= 0 {
+		return nil
+	}
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	b := &bucket{limit: limit, tokens: float64(limit.Burst), last: time.Now()}
+	l.buckets[endpoint] = b
+	return b
+}
+
+// Parse a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(h http.Header) time.Duration {
+
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
@@ -0,0 +1,145 @@
+package efashevdsapigo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBurst(t *testing.T) {
+
+	l := NewRateLimiter(Limit{Rate: 1, Burst: 2}, map[string]Limit{"/balance": {Rate: 100, Burst: 1}})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	for i := range 2 {
+		if err := l.Wait(ctx, "/vend/execute"); err != nil {
+			t.Fatalf("Wait() %d error = %v, want the burst served", i, err)
+		}
+	}
+	// The next token comes in a second, after the deadline.
+	err := l.Wait(ctx, "/vend/execute")
+	var rlErr *RateLimitError
+	if !errors.As(err, &rlErr) || rlErr.RetryAfter <= 0 || rlErr.RetryAfter > time.Second {
+		t.Fatalf("Wait() error = %v, want a RateLimitError retrying within a second", err)
+	}
+
+	// The endpoint limit refills within the deadline.
+	for i := range 3 {
+		if err := l.Wait(ctx, "/balance"); err != nil {
+			t.Fatalf("Wait() %d error = %v, want a token within the deadline", i, err)
+		}
+	}
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+
+	l := NewRateLimiter(Limit{}, map[string]Limit{"/vend/execute": {Rate: 1, Burst: 1}})
+	for range 100 {
+		if err := l.Wait(context.Background(), "/balance"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRateLimiterCancel(t *testing.T) {
+
+	l := NewRateLimiter(Limit{Rate: 10, Burst: 1}, nil)
+	if err := l.Wait(context.Background(), "/balance"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx, "/balance"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait() error = %v, want %v", err, context.Canceled)
+	}
+	// The canceled wait gave its token back.
+	l.mu.Lock()
+	tokens := l.buckets["/balance"].tokens
+	l.mu.Unlock()
+	if tokens < -0.5 {
+		t.Fatalf("bucket has %.2f tokens, want the canceled wait token given back", tokens)
+	}
+}
+
+func TestRateLimiterBackoff(t *testing.T) {
+
+	tests := []struct {
+		name string
+		def  Limit
+	}{
+		{name: "limited", def: Limit{Rate: 100, Burst: 10}},
+		{name: "unlimited"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			l := NewRateLimiter(tt.def, nil)
+			l.Backoff("/vend/execute", time.Minute)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			err := l.Wait(ctx, "/vend/execute")
+			var rlErr *RateLimitError
+			if !errors.As(err, &rlErr) || rlErr.RetryAfter < 59*time.Second {
+				t.Fatalf("Wait() error = %v, want a RateLimitError retrying after the pause", err)
+			}
+			// Other endpoints are not paused.
+			if err := l.Wait(ctx, "/balance"); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "empty", want: 0},
+		{name: "seconds", value: "30", want: 30 * time.Second},
+		{name: "date", value: time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), want: time.Hour},
+		{name: "invalid", value: "soon", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			h := http.Header{}
+			if tt.value != "" {
+				h.Set("Retry-After", tt.value)
+			}
+			// HTTP dates have a second precision.
+			if got := retryAfter(h); got < tt.want-time.Second || got > tt.want {
+				t.Fatalf("retryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTooManyRequestsBacksOff(t *testing.T) {
+
+	api := &testAPI{executeStatus: http.StatusTooManyRequests, retryAfter: "60"}
+	// The endpoint has no configured limit.
+	limiter := NewRateLimiter(Limit{}, nil)
+	c := newTestClient(t, api, WithRateLimiterOption(limiter))
+
+	_, err := c.VendExecute(context.Background(), testExecuteBody)
+	var rlErr *RateLimitError
+	if !errors.As(err, &rlErr) || rlErr.RetryAfter != time.Minute {
+		t.Fatalf("VendExecute() error = %v, want a RateLimitError retrying after a minute", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = c.VendExecute(ctx, testExecuteBody)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("VendExecute() error = %v, want %v", err, ErrRateLimited)
+	}
+	if n := api.executes.Load(); n != 1 {
+		t.Fatalf("executed %d times, want 1", n)
+	}
+}
@@ -0,0 +1,199 @@
+package receipt
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	efashe "github.com/quarksgroup/efashe-vds-api-go"
+)
+
+// ESC/POS commands used by receipts.
+var (
+	escInit        = []byte{0x1b, 0x40}
+	escCodePage    = []byte{0x1b, 0x74, 16} // WPC1252
+	escAlignLeft   = []byte{0x1b, 0x61, 0}
+	escAlignCenter = []byte{0x1b, 0x61, 1}
+	escBoldOn      = []byte{0x1b, 0x45, 1}
+	escBoldOff     = []byte{0x1b, 0x45, 0}
+	escSizeNormal  = []byte{0x1d, 0x21, 0x00}
+	escSizeDouble  = []byte{0x1d, 0x21, 0x11}
+	escFeedCut     = []byte{0x1d, 0x56, 66, 3}
+)
+
+// Write the receipt of a transaction as an ESC/POS byte stream,
+// the voucher is printed in large grouped digits and the receipt number as a QR code.
+func (r *Renderer) ESCPOS(w io.Writer, trx efashe.Transaction) error {
+
+	var (
+		d = r.Data(trx)
+		e = &escpos{width: r.width}
+	)
+	e.write(escInit, escCodePage)
+	r.escposHeader(e, d)
+	e.separator()
+	e.lines(r, d.Header)
+	e.separator()
+	e.lines(r, d.Provider)
+	e.lines(r, d.Details)
+	if d.Voucher != "" {
+		e.separator()
+		e.write(escAlignCenter)
+		e.text(d.Labels.Voucher)
+		e.large(d.Voucher)
+		e.write(escAlignLeft)
+	}
+	if len(d.Deductions) > 0 {
+		e.separator()
+		e.text(d.Labels.Deductions)
+		e.lines(r, d.Deductions)
+	}
+	e.separator()
+	e.write(escAlignCenter)
+	if trx.SpVendInfo.ReceiptNo != "" {
+		e.qrCode(trx.SpVendInfo.ReceiptNo)
+	}
+	e.text(d.Labels.ThankYou)
+	if d.Branding.Footer != "" {
+		e.text(d.Branding.Footer)
+	}
+	e.write(escFeedCut)
+
+	_, err := w.Write(e.buf.Bytes())
+	return err
+}
+
+// Write electricity token records as an ESC/POS byte stream, one record after another with every token in large grouped digits.
+func (r *Renderer) ESCPOSTokens(w io.Writer, tokens []efashe.ElectricityToken) error {
+
+	var (
+		d = r.Data(efashe.Transaction{})
+		e = &escpos{width: r.width}
+	)
+	e.write(escInit, escCodePage)
+	r.escposHeader(e, d)
+	for _, t := range tokens {
+		e.separator()
+		e.lines(r, nonEmpty(
+			Line{d.Labels.AccountNumber, t.MeterNo},
+			Line{d.Labels.Customer, t.CustomerName},
+			Line{d.Labels.Date, t.Tstamp},
+			Line{d.Labels.ReceiptNo, t.ReceiptNo},
+			Line{d.Labels.Amount, FormatAmount(t.Amount, "")},
+			Line{d.Labels.Units, FormatAmount(t.Units, "kWh")},
+		))
+		e.write(escAlignCenter)
+		for _, v := range t.Tokens() {
+			e.text(d.Labels.Voucher)
+			e.large(v)
+		}
+		if t.ReceiptNo != "" {
+			e.qrCode(t.ReceiptNo)
+		}
+		e.write(escAlignLeft)
+	}
+	e.separator()
+	e.write(escAlignCenter)
+	e.text(d.Labels.ThankYou)
+	e.write(escFeedCut)
+
+	_, err := w.Write(e.buf.Bytes())
+	return err
+}
+
+func (r *Renderer) escposHeader(e *escpos, d Data) {
+
+	e.write(escAlignCenter)
+	if d.Branding.Name != "" {
+		e.write(escBoldOn)
+		e.text(strings.ToUpper(d.Branding.Name))
+		e.write(escBoldOff)
+	}
+	for _, l := range d.Branding.Lines {
+		e.text(l)
+	}
+	e.write(escBoldOn)
+	e.text(d.Labels.Title)
+	e.write(escBoldOff, escAlignLeft)
+}
+
+type escpos struct {
+	buf   bytes.Buffer
+	width int
+}
+
+func (e *escpos) write(cmds ...[]byte) {
+
+	for _, c := range cmds {
+		e.buf.Write(c)
+	}
+}
+
+// Write a line of text, characters are encoded to the printer code page and others replaced by '?'.
+func (e *escpos) text(v string) {
+
+	for _, r := range v {
+		if r < 256 && (r >= 32 && r < 127 || r >= 160) {
+			e.buf.WriteByte(byte(r))
+			continue
+		}
+		e.buf.WriteByte('?')
+	}
+	e.buf.WriteByte('\n')
+}
+
+func (e *escpos) separator() {
+	e.text(strings.Repeat("-", e.width))
+}
+
+func (e *escpos) lines(r *Renderer, lines []Line) {
+
+	var b strings.Builder
+	r.lines(&b, lines)
+	for _, l := range strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n") {
+		if l != "" {
+			e.text(l)
+		}
+	}
+}
+
+// Write a token in double width and height, digits are grouped by 4 and wrapped at group boundaries.
+func (e *escpos) large(token string) {
+
+	var (
+		maxLen = e.width / 2
+		line   string
+	)
+	e.write(escBoldOn, escSizeDouble)
+	for _, g := range strings.Fields(efashe.FormatToken(token)) {
+		switch {
+		case line == "":
+			line = g
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(g) <= maxLen:
+			line += " " + g
+		default:
+			e.text(line)
+			line = g
+		}
+	}
+	if line != "" {
+		e.text(line)
+	}
+	e.write(escSizeNormal, escBoldOff)
+}
+
+// Print a QR code with model 2, module size 6 and error correction level M.
+func (e *escpos) qrCode(data string) {
+
+	n := len(data) + 3
+	e.write(
+		[]byte{0x1d, 0x28, 0x6b, 4, 0, 0x31, 0x41, 0x32, 0x00},
+		[]byte{0x1d, 0x28, 0x6b, 3, 0, 0x31, 0x43, 6},
+		[]byte{0x1d, 0x28, 0x6b, 3, 0, 0x31, 0x45, 0x31},
+		[]byte{0x1d, 0x28, 0x6b, byte(n), byte(n >> 8), 0x31, 0x50, 0x30},
+		[]byte(data),
+		[]byte{0x1d, 0x28, 0x6b, 3, 0, 0x31, 0x51, 0x30},
+	)
+	e.buf.WriteByte('\n')
+}
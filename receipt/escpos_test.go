@@ -0,0 +1,112 @@
+package receipt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	efashe "github.com/quarksgroup/efashe-vds-api-go"
+)
+
+func testTransaction() efashe.Transaction {
+
+	return efashe.Transaction{
+		TransactionId:         "trx-1",
+		TransactionStatusId:   efashe.TransactionSuccessedState,
+		CustomerAccountNumber: "04123456789",
+		CustomerAccountName:   "Jean Bosco",
+		UpdatedAt:             "2024-05-01 10:00:00",
+		Amount:                5000,
+		Currency:              "RWF",
+		SpVendInfo: efashe.SPVendInfo{
+			SpName:    "EUCL",
+			ReceiptNo: "RCPT-001",
+			Voucher:   "12345678901234567890",
+			Units:     "21.5",
+			Deductions: []efashe.Deductions{
+				{DeductionName: "VAT", RateType: efashe.PercentageRateType, Rate: "18", AmountDeducted: 763},
+			},
+		},
+		OurVendInfo: efashe.AgencyVendInfo{AgencyName: "Shop", BranchName: "Main/HQ"},
+	}
+}
+
+func TestESCPOS(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		width int
+		// The token lines in double size.
+		token string
+	}{
+		{name: "58mm", width: Width58mm, token: "1234 5678 9012\n3456 7890\n"},
+		{name: "80mm", width: Width80mm, token: "1234 5678 9012 3456 7890\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var buf bytes.Buffer
+			err := New(WithWidthOption(tt.width)).ESCPOS(&buf, testTransaction())
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := buf.Bytes()
+
+			// Initialize and select the WPC1252 code page.
+			if init := []byte{0x1b, 0x40, 0x1b, 0x74, 16}; !bytes.HasPrefix(out, init) {
+				t.Errorf("output starts with % x, want % x", out[:min(len(out), len(init))], init)
+			}
+			token := append([]byte{0x1b, 0x45, 1, 0x1d, 0x21, 0x11}, tt.token...)
+			token = append(token, 0x1d, 0x21, 0x00, 0x1b, 0x45, 0)
+			if !bytes.Contains(out, token) {
+				t.Errorf("output has no double size token block % x", token)
+			}
+			qr := append([]byte{0x1d, 0x28, 0x6b, 11, 0, 0x31, 0x50, 0x30}, "RCPT-001"...)
+			if !bytes.Contains(out, qr) {
+				t.Errorf("output has no QR code data % x", qr)
+			}
+			if sep := strings.Repeat("-", tt.width) + "\n"; !bytes.Contains(out, []byte(sep)) {
+				t.Errorf("output has no %d characters separator", tt.width)
+			}
+			// Feed and cut.
+			if cut := []byte{0x1d, 0x56, 66, 3}; !bytes.HasSuffix(out, cut) {
+				t.Errorf("output ends with % x, want % x", out[max(0, len(out)-len(cut)):], cut)
+			}
+		})
+	}
+}
+
+func TestESCPOSQRCodeLength(t *testing.T) {
+
+	data := strings.Repeat("A", 300)
+	e := &escpos{width: Width58mm}
+	e.qrCode(data)
+
+	// 300 bytes of data plus 3 is 0x012f, stored low byte first.
+	store := append([]byte{0x1d, 0x28, 0x6b, 0x2f, 0x01, 0x31, 0x50, 0x30}, data...)
+	if !bytes.Contains(e.buf.Bytes(), store) {
+		t.Fatalf("QR code store command not found in % x", e.buf.Bytes()[:32])
+	}
+}
+
+func TestESCPOSTokens(t *testing.T) {
+
+	tokens := []efashe.ElectricityToken{
+		{MeterNo: "04123456789", ReceiptNo: "RCPT-001", Token: "12345678901234567890", Amount: 5000, Units: 21.5},
+	}
+	var buf bytes.Buffer
+	err := New(WithWidthOption(Width80mm)).ESCPOSTokens(&buf, tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.Bytes()
+	if !bytes.HasPrefix(out, []byte{0x1b, 0x40, 0x1b, 0x74, 16}) {
+		t.Error("output does not start with the init and code page commands")
+	}
+	if !bytes.Contains(out, []byte{0x1d, 0x21, 0x11}) || !bytes.Contains(out, []byte("1234 5678 9012 3456 7890\n")) {
+		t.Error("output has no double size token")
+	}
+	if !bytes.HasSuffix(out, []byte{0x1d, 0x56, 66, 3}) {
+		t.Error("output does not end with the cut command")
+	}
+}
@@ -0,0 +1,43 @@
+package receipt
+
+import "html/template"
+
+var defaultHTMLTemplate = template.Must(template.New("receipt").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Labels.Title}} {{.Transaction.TransactionId}}</title>
+<style>
+body { font-family: monospace; max-width: 360px; margin: 0 auto; }
+.center { text-align: center; }
+table { width: 100%; border-collapse: collapse; }
+td:last-child { text-align: right; }
+hr { border: 0; border-top: 1px dashed #000; }
+.voucher { font-size: 1.4em; font-weight: bold; letter-spacing: 2px; }
+</style>
+</head>
+<body>
+<div class="center">
+{{- with .Branding.LogoURL}}<img src="{{.}}" alt="" height="48"><br>{{end}}
+{{- with .Branding.Name}}<strong>{{.}}</strong><br>{{end}}
+{{- range .Branding.Lines}}{{.}}<br>{{end}}
+<h3>{{.Labels.Title}}</h3>
+</div>
+<hr>
+<table>{{range .Header}}<tr><td>{{.Label}}</td><td>{{.Value}}</td></tr>{{end}}</table>
+<hr>
+<table>{{range .Provider}}<tr><td>{{.Label}}</td><td>{{.Value}}</td></tr>{{end}}{{range .Details}}<tr><td>{{.Label}}</td><td>{{.Value}}</td></tr>{{end}}</table>
+{{- if .Voucher}}
+<hr>
+<div class="center">{{.Labels.Voucher}}<br><span class="voucher">{{.Voucher}}</span></div>
+{{- end}}
+{{- if .Deductions}}
+<hr>
+<div>{{.Labels.Deductions}}</div>
+<table>{{range .Deductions}}<tr><td>{{.Label}}</td><td>{{.Value}}</td></tr>{{end}}</table>
+{{- end}}
+<hr>
+<div class="center">{{.Labels.ThankYou}}{{with .Branding.Footer}}<br>{{.}}{{end}}</div>
+</body>
+</html>
+`))
@@ -0,0 +1,133 @@
+package receipt
+
+type Language string
+
+const (
+	English     Language = "en"
+	Kinyarwanda Language = "rw"
+	French      Language = "fr"
+)
+
+// Labels printed in front of each receipt value.
+type Labels struct {
+	Title           string
+	Agency          string
+	Branch          string
+	Staff           string
+	ServiceProvider string
+	TIN             string
+	VatNo           string
+	ReceiptNo       string
+	TransactionId   string
+	Date            string
+	Customer        string
+	AccountNumber   string
+	Amount          string
+	Units           string
+	Voucher         string
+	Deductions      string
+	Status          string
+	ThankYou        string
+}
+
+var labels = map[Language]Labels{
+	English: {
+		Title:           "RECEIPT",
+		Agency:          "Agency",
+		Branch:          "Branch",
+		Staff:           "Served by",
+		ServiceProvider: "Provider",
+		TIN:             "TIN",
+		VatNo:           "VAT No",
+		ReceiptNo:       "Receipt No",
+		TransactionId:   "Trx ID",
+		Date:            "Date",
+		Customer:        "Customer",
+		AccountNumber:   "Account No",
+		Amount:          "Amount",
+		Units:           "Units",
+		Voucher:         "Token",
+		Deductions:      "Deductions",
+		Status:          "Status",
+		ThankYou:        "Thank you",
+	},
+	Kinyarwanda: {
+		Title:           "INYEMEZABWISHYU",
+		Agency:          "Ikigo",
+		Branch:          "Ishami",
+		Staff:           "Umukozi",
+		ServiceProvider: "Utanga serivisi",
+		TIN:             "TIN",
+		VatNo:           "Nomero ya VAT",
+		ReceiptNo:       "Nomero y'inyemezabwishyu",
+		TransactionId:   "Nomero y'igikorwa",
+		Date:            "Itariki",
+		Customer:        "Umukiriya",
+		AccountNumber:   "Nomero ya konti",
+		Amount:          "Amafaranga",
+		Units:           "Ingano",
+		Voucher:         "Tokeni",
+		Deductions:      "Ibyakuweho",
+		Status:          "Imiterere",
+		ThankYou:        "Murakoze",
+	},
+	French: {
+		Title:           "REÇU",
+		Agency:          "Agence",
+		Branch:          "Succursale",
+		Staff:           "Servi par",
+		ServiceProvider: "Fournisseur",
+		TIN:             "NIF",
+		VatNo:           "N° TVA",
+		ReceiptNo:       "N° de reçu",
+		TransactionId:   "N° de transaction",
+		Date:            "Date",
+		Customer:        "Client",
+		AccountNumber:   "N° de compte",
+		Amount:          "Montant",
+		Units:           "Unités",
+		Voucher:         "Jeton",
+		Deductions:      "Déductions",
+		Status:          "Statut",
+		ThankYou:        "Merci",
+	},
+}
+
+// Labels of a language, English labels are returned for unknown languages.
+func LabelsFor(lang Language) Labels {
+
+	l, ok := labels[lang]
+	if !ok {
+		return labels[English]
+	}
+	return l
+}
+
+// Fill empty labels with the ones of fallback.
+func (l Labels) merge(fallback Labels) Labels {
+
+	set := func(v *string, def string) {
+		if *v == "" {
+			*v = def
+		}
+	}
+	set(&l.Title, fallback.Title)
+	set(&l.Agency, fallback.Agency)
+	set(&l.Branch, fallback.Branch)
+	set(&l.Staff, fallback.Staff)
+	set(&l.ServiceProvider, fallback.ServiceProvider)
+	set(&l.TIN, fallback.TIN)
+	set(&l.VatNo, fallback.VatNo)
+	set(&l.ReceiptNo, fallback.ReceiptNo)
+	set(&l.TransactionId, fallback.TransactionId)
+	set(&l.Date, fallback.Date)
+	set(&l.Customer, fallback.Customer)
+	set(&l.AccountNumber, fallback.AccountNumber)
+	set(&l.Amount, fallback.Amount)
+	set(&l.Units, fallback.Units)
+	set(&l.Voucher, fallback.Voucher)
+	set(&l.Deductions, fallback.Deductions)
+	set(&l.Status, fallback.Status)
+	set(&l.ThankYou, fallback.ThankYou)
+	return l
+}
@@ -0,0 +1,58 @@
+package receipt
+
+import "html/template"
+
+type Option interface {
+	value() any
+}
+
+type languageOption Language
+
+func (opt languageOption) value() any { return opt }
+
+// Labels language of the receipt, English is used by default.
+func WithLanguageOption(lang Language) Option {
+	return languageOption(lang)
+}
+
+type labelsOption struct {
+	v Labels
+}
+
+func (opt labelsOption) value() any { return opt.v }
+
+// Override the labels of the receipt, empty labels fall back to the receipt language ones.
+func WithLabelsOption(labels Labels) Option {
+	return labelsOption{v: labels}
+}
+
+type widthOption int
+
+func (opt widthOption) value() any { return opt }
+
+// Number of characters per line of text and PDF receipts, check Width58mm and Width80mm.
+func WithWidthOption(width int) Option {
+	return widthOption(width)
+}
+
+type brandingOption struct {
+	v Branding
+}
+
+func (opt brandingOption) value() any { return opt.v }
+
+// Attach the business branding printed on the receipt.
+func WithBrandingOption(branding Branding) Option {
+	return brandingOption{v: branding}
+}
+
+type htmlTemplateOption struct {
+	v *template.Template
+}
+
+func (opt htmlTemplateOption) value() any { return opt.v }
+
+// Custom HTML template executed with a Data value instead of the default one.
+func WithHTMLTemplateOption(tmpl *template.Template) Option {
+	return htmlTemplateOption{v: tmpl}
+}
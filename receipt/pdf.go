@@ -0,0 +1,79 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+const (
+	pdfFontSize = 9.0
+	// Courier glyphs are 600/1000 of the font size wide.
+	pdfCharWidth = pdfFontSize * 0.6
+	pdfLeading   = 11.0
+	pdfMargin    = 12.0
+)
+
+// Write a single page PDF with the lines in Courier, the page is sized to fit the lines.
+func writePDF(w io.Writer, lines []string, width int) error {
+
+	var (
+		pageW = pdfMargin*2 + float64(width)*pdfCharWidth
+		pageH = pdfMargin*2 + float64(len(lines))*pdfLeading
+	)
+
+	var content bytes.Buffer
+	// The ' operator moves to the next line before showing text, so start a line above the first one.
+	fmt.Fprintf(&content, "BT /F1 %.0f Tf %.1f TL %.1f %.1f Td\n", pdfFontSize, pdfLeading, pdfMargin, pageH-pdfMargin-pdfFontSize+pdfLeading)
+	for _, l := range lines {
+		fmt.Fprintf(&content, "(%s) '\n", pdfString(l))
+	}
+	content.WriteString("ET\n")
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.1f %.1f] /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>", pageW, pageH),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+	}
+
+	var (
+		buf     bytes.Buffer
+		offsets = make([]int, len(objects))
+	)
+	buf.WriteString("%PDF-1.4\n")
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Escape a PDF literal string, characters outside Latin-1 are replaced by '?'.
+func pdfString(v string) string {
+
+	var b bytes.Buffer
+	for _, r := range v {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 32 && r < 127:
+			b.WriteRune(r)
+		case r >= 160 && r < 256:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
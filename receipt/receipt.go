@@ -0,0 +1,252 @@
+// Package receipt renders completed vend transactions into printable receipts.
+package receipt
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	efashe "github.com/quarksgroup/efashe-vds-api-go"
+)
+
+const (
+	// Characters per line of a 58mm thermal printer paper.
+	Width58mm = 32
+	// Characters per line of a 80mm thermal printer paper.
+	Width80mm = 48
+)
+
+// Business details printed on top and at the bottom of receipts.
+type Branding struct {
+	Name string
+	// Extra header lines such as address or phone number.
+	Lines []string
+	// Printed after the thank you note.
+	Footer string
+	// Only used by the HTML receipt.
+	LogoURL string
+}
+
+// A labelled value of a receipt.
+type Line struct {
+	Label string
+	Value string
+}
+
+// Data is what a receipt is rendered from, it is also the value custom HTML templates are executed with.
+type Data struct {
+	Labels      Labels
+	Branding    Branding
+	Transaction efashe.Transaction
+	// Agency, branch, staff, date and transaction id.
+	Header []Line
+	// Service provider name, TIN, VAT number and receipt number.
+	Provider []Line
+	// Customer, amount, units and status.
+	Details []Line
+	// The voucher or token, empty when the product is not voucher based.
+	Voucher    string
+	Deductions []Line
+}
+
+type Renderer struct {
+	width    int
+	labels   Labels
+	branding Branding
+	html     *template.Template
+}
+
+func New(opts ...Option) *Renderer {
+
+	var (
+		r      = &Renderer{width: Width58mm, html: defaultHTMLTemplate}
+		lang   = English
+		custom Labels
+	)
+	for _, opt := range opts {
+		switch opt := opt.(type) {
+		case languageOption:
+			lang = Language(opt)
+		case labelsOption:
+			custom = opt.v
+		case widthOption:
+			if opt > 0 {
+				r.width = int(opt)
+			}
+		case brandingOption:
+			r.branding = opt.v
+		case htmlTemplateOption:
+			r.html = opt.v
+		}
+	}
+	r.labels = custom.merge(LabelsFor(lang))
+	return r
+}
+
+// The receipt data of a transaction.
+func (r *Renderer) Data(trx efashe.Transaction) Data {
+
+	l := r.labels
+	d := Data{
+		Labels:      l,
+		Branding:    r.branding,
+		Transaction: trx,
+		Voucher:     trx.SpVendInfo.FormattedVoucher(),
+	}
+	d.Header = nonEmpty(
+		Line{l.Agency, trx.OurVendInfo.AgencyName},
+		Line{l.Branch, trx.OurVendInfo.BranchName},
+		Line{l.Staff, trx.OurVendInfo.StaffName},
+		Line{l.TIN, trx.OurVendInfo.OurTIN},
+		Line{l.Date, trx.UpdatedAt},
+		Line{l.TransactionId, trx.TransactionId},
+	)
+	d.Provider = nonEmpty(
+		Line{l.ServiceProvider, trx.SpVendInfo.SpName},
+		Line{l.TIN, trx.SpVendInfo.TIN},
+		Line{l.VatNo, trx.SpVendInfo.VatNo},
+		Line{l.ReceiptNo, trx.SpVendInfo.ReceiptNo},
+	)
+	d.Details = nonEmpty(
+		Line{l.Customer, trx.CustomerAccountName},
+		Line{l.AccountNumber, trx.CustomerAccountNumber},
+		Line{l.Amount, FormatAmount(trx.Amount, trx.Currency)},
+		Line{l.Units, trx.SpVendInfo.Units},
+		Line{l.Status, trx.TransactionStatusId},
+	)
+	for _, ded := range slices.Concat(trx.SpVendInfo.Deductions, trx.OurVendInfo.OurDeductions) {
+		name := ded.DeductionName
+		if ded.RateType == efashe.PercentageRateType && ded.Rate != "" {
+			name = fmt.Sprintf("%s (%s%%)", name, strings.TrimSuffix(ded.Rate, "%"))
+		}
+		d.Deductions = append(d.Deductions, Line{name, FormatAmount(ded.AmountDeducted, trx.Currency)})
+	}
+	return d
+}
+
+// Write a fixed-width text receipt suited to thermal printers.
+func (r *Renderer) Text(w io.Writer, trx efashe.Transaction) error {
+
+	_, err := io.WriteString(w, r.text(r.Data(trx)))
+	return err
+}
+
+// Write an HTML receipt.
+func (r *Renderer) HTML(w io.Writer, trx efashe.Transaction) error {
+	return r.html.Execute(w, r.Data(trx))
+}
+
+// Write a single page PDF of the text receipt.
+func (r *Renderer) PDF(w io.Writer, trx efashe.Transaction) error {
+	return writePDF(w, strings.Split(strings.TrimRight(r.text(r.Data(trx)), "\n"), "\n"), r.width)
+}
+
+func (r *Renderer) text(d Data) string {
+
+	var (
+		b   strings.Builder
+		sep = strings.Repeat("-", r.width)
+	)
+	if d.Branding.Name != "" {
+		r.center(&b, strings.ToUpper(d.Branding.Name))
+	}
+	for _, l := range d.Branding.Lines {
+		r.center(&b, l)
+	}
+	r.center(&b, d.Labels.Title)
+	b.WriteString(sep + "\n")
+	r.lines(&b, d.Header)
+	b.WriteString(sep + "\n")
+	r.lines(&b, d.Provider)
+	r.lines(&b, d.Details)
+	if d.Voucher != "" {
+		b.WriteString(sep + "\n")
+		r.center(&b, d.Labels.Voucher)
+		r.center(&b, d.Voucher)
+	}
+	if len(d.Deductions) > 0 {
+		b.WriteString(sep + "\n")
+		b.WriteString(d.Labels.Deductions + "\n")
+		r.lines(&b, d.Deductions)
+	}
+	b.WriteString(sep + "\n")
+	r.center(&b, d.Labels.ThankYou)
+	if d.Branding.Footer != "" {
+		r.center(&b, d.Branding.Footer)
+	}
+	return b.String()
+}
+
+// Write label and value on one line with the value right aligned,
+// the value goes on its own lines when both do not fit.
+func (r *Renderer) lines(b *strings.Builder, lines []Line) {
+
+	for _, l := range lines {
+		lw, vw := utf8.RuneCountInString(l.Label), utf8.RuneCountInString(l.Value)
+		if lw+1+vw <= r.width {
+			b.WriteString(l.Label + strings.Repeat(" ", r.width-lw-vw) + l.Value + "\n")
+			continue
+		}
+		b.WriteString(l.Label + "\n")
+		for _, chunk := range chunks(l.Value, r.width) {
+			b.WriteString(strings.Repeat(" ", r.width-utf8.RuneCountInString(chunk)) + chunk + "\n")
+		}
+	}
+}
+
+func (r *Renderer) center(b *strings.Builder, v string) {
+
+	for _, chunk := range chunks(v, r.width) {
+		pad := (r.width - utf8.RuneCountInString(chunk)) / 2
+		b.WriteString(strings.Repeat(" ", pad) + chunk + "\n")
+	}
+}
+
+// Format an amount with thousands separators followed by the currency, decimals are only kept when not zero.
+func FormatAmount(amount float64, currency string) string {
+
+	v := strconv.FormatFloat(amount, 'f', 2, 64)
+	v = strings.TrimSuffix(v, ".00")
+	intPart, frac, _ := strings.Cut(v, ".")
+	neg := strings.HasPrefix(intPart, "-")
+	intPart = strings.TrimPrefix(intPart, "-")
+	for i := len(intPart) - 3; i > 0; i -= 3 {
+		intPart = intPart[:i] + "," + intPart[i:]
+	}
+	if neg {
+		intPart = "-" + intPart
+	}
+	if frac != "" {
+		intPart += "." + frac
+	}
+	return strings.TrimSpace(intPart + " " + currency)
+}
+
+func chunks(v string, width int) []string {
+
+	runes := []rune(v)
+	if len(runes) == 0 {
+		return []string{""}
+	}
+	var res []string
+	for len(runes) > width {
+		res = append(res, string(runes[:width]))
+		runes = runes[width:]
+	}
+	return append(res, string(runes))
+}
+
+func nonEmpty(lines ...Line) []Line {
+
+	res := lines[:0]
+	for _, l := range lines {
+		if strings.TrimSpace(l.Value) != "" {
+			res = append(res, l)
+		}
+	}
+	return res
+}
@@ -0,0 +1,142 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestText(t *testing.T) {
+
+	for _, width := range []int{Width58mm, Width80mm} {
+		t.Run(strconv.Itoa(width), func(t *testing.T) {
+
+			var b strings.Builder
+			err := New(WithWidthOption(width), WithBrandingOption(Branding{Name: "Shop", Footer: "Murakoze"})).Text(&b, testTransaction())
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := b.String()
+			for _, l := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+				if n := utf8.RuneCountInString(l); n > width {
+					t.Errorf("line %q is %d characters wide, want at most %d", l, n, width)
+				}
+			}
+			for _, want := range []string{
+				"Amount" + strings.Repeat(" ", width-len("Amount")-len("5,000 RWF")) + "5,000 RWF\n",
+				center("1234 5678 9012 3456 7890", width),
+				"VAT (18%)",
+				center("SHOP", width),
+				center("Murakoze", width),
+			} {
+				if !strings.Contains(out, want) {
+					t.Errorf("receipt has no %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
+
+func center(v string, width int) string {
+	return strings.Repeat(" ", (width-len(v))/2) + v + "\n"
+}
+
+func TestTextLanguage(t *testing.T) {
+
+	var b strings.Builder
+	err := New(WithLanguageOption(French), WithLabelsOption(Labels{ThankYou: "À bientôt"})).Text(&b, testTransaction())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"REÇU", "Montant", "À bientôt"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("receipt has no %q:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestHTML(t *testing.T) {
+
+	trx := testTransaction()
+	trx.CustomerAccountName = "<script>alert(1)</script>"
+	var b strings.Builder
+	err := New().HTML(&b, trx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	if strings.Contains(out, "<script>") {
+		t.Error("customer name is not escaped")
+	}
+	for _, want := range []string{
+		`<span class="voucher">1234 5678 9012 3456 7890</span>`,
+		"<tr><td>Amount</td><td>5,000 RWF</td></tr>",
+		"<tr><td>VAT (18%)</td><td>763 RWF</td></tr>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("receipt has no %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestPDF(t *testing.T) {
+
+	trx := testTransaction()
+	trx.CustomerAccountName = "Café (Kigali)"
+	var buf bytes.Buffer
+	err := New().PDF(&buf, trx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "%PDF-1.4\n") || !strings.HasSuffix(out, "%%EOF\n") {
+		t.Fatalf("not a PDF:\n%s", out)
+	}
+	if !strings.Contains(out, `Caf\351 \(Kigali\)`) {
+		t.Error("customer name is not escaped as a PDF string")
+	}
+
+	// Every cross-reference entry points to its object.
+	xref := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(out)
+	if xref == nil {
+		t.Fatal("no startxref")
+	}
+	start, _ := strconv.Atoi(xref[1])
+	if !strings.HasPrefix(out[start:], "xref\n0 6\n") {
+		t.Fatalf("startxref %d does not point to the cross-reference table", start)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(out[start:], -1)
+	if len(entries) != 5 {
+		t.Fatalf("cross-reference table has %d objects, want 5", len(entries))
+	}
+	for i, e := range entries {
+		off, _ := strconv.Atoi(e[1])
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !strings.HasPrefix(out[off:], want) {
+			t.Errorf("object %d offset %d points to %q", i+1, off, out[off:off+len(want)])
+		}
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+
+	tests := []struct {
+		amount   float64
+		currency string
+		want     string
+	}{
+		{amount: 0, want: "0"},
+		{amount: 999, currency: "RWF", want: "999 RWF"},
+		{amount: 1000, currency: "RWF", want: "1,000 RWF"},
+		{amount: 1234567.5, currency: "RWF", want: "1,234,567.50 RWF"},
+		{amount: -1500, want: "-1,500"},
+	}
+	for _, tt := range tests {
+		if got := FormatAmount(tt.amount, tt.currency); got != tt.want {
+			t.Errorf("FormatAmount(%v, %q) = %q, want %q", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package reconcile
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+var csvHeader = []string{
+	"status", "trx_id",
+	"local_amount", "local_account", "local_timestamp",
+	"upstream_state", "upstream_amount", "upstream_currency", "upstream_account", "upstream_created_at",
+	"error",
+}
+
+// Write the report items as CSV with a header row.
+func (r *Report) WriteCSV(w io.Writer) error {
+
+	cw := csv.NewWriter(w)
+	err := cw.Write(csvHeader)
+	if err != nil {
+		return err
+	}
+	for _, it := range r.Items {
+		row := make([]string, len(csvHeader))
+		row[0], row[1], row[10] = string(it.Status), it.TransactionId, it.Error
+		if it.Local != nil {
+			row[2] = strconv.FormatFloat(it.Local.Amount, 'f', -1, 64)
+			row[3] = it.Local.CustomerAccountNumber
+			if !it.Local.Timestamp.IsZero() {
+				row[4] = it.Local.Timestamp.Format(time.RFC3339)
+			}
+		}
+		if it.Upstream != nil {
+			row[5] = it.Upstream.TransactionStatusId
+			row[6] = strconv.FormatFloat(it.Upstream.Amount, 'f', -1, 64)
+			row[7] = it.Upstream.Currency
+			row[8] = it.Upstream.CustomerAccountNumber
+			row[9] = it.Upstream.CreatedAt
+		}
+		err = cw.Write(row)
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Write the report as indented JSON with a per status summary.
+func (r *Report) WriteJSON(w io.Writer) error {
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		*Report
+		Summary map[Status]int `json:"summary"`
+	}{r, r.Summary()})
+}
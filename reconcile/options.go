@@ -0,0 +1,46 @@
+package reconcile
+
+import efashe "github.com/quarksgroup/efashe-vds-api-go"
+
+type Option interface {
+	value() any
+}
+
+type concurrencyOption int
+
+func (opt concurrencyOption) value() any { return opt }
+
+// Maximum number of transaction status requests in flight, DefaultConcurrency is used by default.
+func WithConcurrencyOption(n int) Option {
+	return concurrencyOption(n)
+}
+
+type amountToleranceOption float64
+
+func (opt amountToleranceOption) value() any { return opt }
+
+// Maximum difference between local and upstream amounts still considered a match.
+func WithAmountToleranceOption(tolerance float64) Option {
+	return amountToleranceOption(tolerance)
+}
+
+type historyFilterOption struct {
+	v efashe.TransactionFilter
+}
+
+func (opt historyFilterOption) value() any { return opt.v }
+
+// Narrow the upstream history walked to find transactions missing locally, e.g to a branch or a vertical.
+// Its date range is replaced by the one being reconciled.
+func WithHistoryFilterOption(filter efashe.TransactionFilter) Option {
+	return historyFilterOption{v: filter}
+}
+
+type clientOptions []efashe.Option
+
+func (opt clientOptions) value() any { return opt }
+
+// Options passed to every client call.
+func WithClientOptions(opts ...efashe.Option) Option {
+	return clientOptions(opts)
+}
@@ -0,0 +1,207 @@
+// Package reconcile compares locally recorded sales against Efashe transactions.
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	efashe "github.com/quarksgroup/efashe-vds-api-go"
+)
+
+const (
+	DefaultConcurrency     = 8
+	DefaultAmountTolerance = 0.005
+)
+
+type Status string
+
+const (
+	Matched Status = "matched"
+	// The upstream transaction amount differs from the local one.
+	AmountMismatch Status = "amount_mismatch"
+	// The upstream transaction belongs to another customer account.
+	AccountMismatch Status = "account_mismatch"
+	// The upstream transaction failed or timed out while it is recorded locally as sold.
+	FailedUpstream Status = "failed_upstream"
+	// The upstream transaction is not final yet.
+	PendingUpstream Status = "pending_upstream"
+	MissingUpstream Status = "missing_upstream"
+	// A successful upstream transaction without local record.
+	MissingLocal Status = "missing_local"
+	// The upstream transaction could not be looked up.
+	Unresolved Status = "unresolved"
+)
+
+// A sale as recorded locally.
+type Record struct {
+	TransactionId         string    `json:"trxId"`
+	Amount                float64   `json:"amount"`
+	CustomerAccountNumber string    `json:"customerAccountNumber"`
+	Timestamp             time.Time `json:"timestamp"`
+}
+
+type Item struct {
+	Status        Status `json:"status"`
+	TransactionId string `json:"trxId"`
+	// Nil for transactions missing locally.
+	Local *Record `json:"local,omitempty"`
+	// Nil for transactions missing or unresolved upstream.
+	Upstream *efashe.Transaction `json:"upstream,omitempty"`
+	Error    string              `json:"error,omitempty"`
+}
+
+type Report struct {
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"`
+	Items []Item    `json:"items"`
+}
+
+// Items with the status.
+func (r *Report) Filter(status Status) []Item {
+
+	var res []Item
+	for _, it := range r.Items {
+		if it.Status == status {
+			res = append(res, it)
+		}
+	}
+	return res
+}
+
+// Number of items per status.
+func (r *Report) Summary() map[Status]int {
+
+	res := map[Status]int{}
+	for _, it := range r.Items {
+		res[it.Status]++
+	}
+	return res
+}
+
+type Reconciler struct {
+	client      efashe.Client
+	concurrency int
+	tolerance   float64
+	filter      efashe.TransactionFilter
+	clientOpts  []efashe.Option
+}
+
+func New(client efashe.Client, opts ...Option) *Reconciler {
+
+	r := &Reconciler{
+		client:      client,
+		concurrency: DefaultConcurrency,
+		tolerance:   DefaultAmountTolerance,
+	}
+	for _, opt := range opts {
+		switch opt := opt.(type) {
+		case concurrencyOption:
+			if opt > 0 {
+				r.concurrency = int(opt)
+			}
+		case amountToleranceOption:
+			r.tolerance = float64(opt)
+		case historyFilterOption:
+			r.filter = opt.v
+		case clientOptions:
+			r.clientOpts = append(r.clientOpts, opt...)
+		}
+	}
+	return r
+}
+
+// Look up the status of every record and, when the period is set, walk the upstream history of the period
+// to find the successful transactions missing locally. The period is from included to to excluded, a zero bound leaves it open.
+// Lookup failures are reported as unresolved items, the returned error is about walking the history or ctx.
+func (r *Reconciler) Reconcile(ctx context.Context, records []Record, from, to time.Time) (*Report, error) {
+
+	report := &Report{From: from, To: to, Items: make([]Item, len(records))}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, r.concurrency)
+	)
+	for i, rec := range records {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, ctx.Err()
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			report.Items[i] = r.lookup(ctx, rec)
+		}()
+	}
+	wg.Wait()
+
+	if from.IsZero() && to.IsZero() {
+		return report, nil
+	}
+
+	local := make(map[string]bool, len(records))
+	for _, rec := range records {
+		local[rec.TransactionId] = true
+	}
+	filter := r.filter
+	filter.From, filter.To = from, to
+	for trx, err := range efashe.AllTransactions(ctx, r.client, filter, r.clientOpts...) {
+		if err != nil {
+			return report, err
+		}
+		if local[trx.TransactionId] || trx.TransactionStatusId != efashe.TransactionSuccessedState || !inPeriod(trx.Transaction, from, to) {
+			continue
+		}
+		upstream := trx.Transaction
+		report.Items = append(report.Items, Item{Status: MissingLocal, TransactionId: trx.TransactionId, Upstream: &upstream})
+	}
+	return report, nil
+}
+
+// The upstream history is filtered on whole days, transactions of the period days but outside the period are dropped.
+// Transactions with an unknown creation time are kept.
+func inPeriod(trx efashe.Transaction, from, to time.Time) bool {
+
+	created, ok := trx.CreatedTime()
+	if !ok {
+		return true
+	}
+	return (from.IsZero() || !created.Before(from)) && (to.IsZero() || created.Before(to))
+}
+
+func (r *Reconciler) lookup(ctx context.Context, rec Record) Item {
+
+	it := Item{TransactionId: rec.TransactionId, Local: &rec}
+	res, err := r.client.VendTransactionStatus(ctx, rec.TransactionId, r.clientOpts...)
+	switch {
+	case errors.Is(err, efashe.ErrTransactionNotFound):
+		it.Status = MissingUpstream
+		return it
+	case err != nil:
+		it.Status, it.Error = Unresolved, err.Error()
+		return it
+	}
+
+	trx := res.Data
+	it.Upstream = &trx
+	switch {
+	case trx.TransactionStatusId == efashe.TransactionFailedState || trx.TransactionStatusId == efashe.TransactionTimeoutState:
+		it.Status = FailedUpstream
+	case !efashe.IsTerminalState(trx.TransactionStatusId):
+		it.Status = PendingUpstream
+	case math.Abs(trx.Amount-rec.Amount) > r.tolerance:
+		it.Status = AmountMismatch
+	case rec.CustomerAccountNumber != "" && trx.CustomerAccountNumber != rec.CustomerAccountNumber:
+		it.Status = AccountMismatch
+	default:
+		it.Status = Matched
+	}
+	return it
+}
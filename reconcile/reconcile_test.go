@@ -0,0 +1,131 @@
+package reconcile
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	efashe "github.com/quarksgroup/efashe-vds-api-go"
+)
+
+var kigali = time.FixedZone("CAT", 2*60*60)
+
+// Answers statuses from trxs and lists them as a single history page.
+type testClient struct {
+	efashe.Client
+	trxs    map[string]efashe.Transaction
+	history []efashe.TransactionRecord
+	filter  efashe.TransactionFilter
+}
+
+func (c *testClient) VendTransactionStatus(_ context.Context, transactionId string, _ ...efashe.Option) (*efashe.VendTransactionStatusResp, error) {
+
+	trx, ok := c.trxs[transactionId]
+	if !ok {
+		return nil, efashe.ErrTransactionNotFound
+	}
+	return &efashe.VendTransactionStatusResp{Data: trx}, nil
+}
+
+func (c *testClient) ListTransactions(_ context.Context, filter efashe.TransactionFilter, _ ...efashe.Option) (*efashe.ListTransactionsResp, error) {
+
+	c.filter = filter
+	res := &efashe.ListTransactionsResp{Pagination: efashe.Pagination{TotalPages: 1}}
+	if filter.Page == 1 {
+		res.Data = c.history
+	}
+	return res, nil
+}
+
+func transaction(id, state string, amount float64, createdAt string) efashe.Transaction {
+	return efashe.Transaction{TransactionId: id, TransactionStatusId: state, Amount: amount, CustomerAccountNumber: "0780000000", CreatedAt: createdAt}
+}
+
+func TestInPeriodEdges(t *testing.T) {
+
+	// A Kigali day, 2024-04-30 22:00 to 2024-05-01 22:00 UTC.
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, kigali)
+	to := from.AddDate(0, 0, 1)
+	tests := []struct {
+		createdAt string
+		want      bool
+	}{
+		{createdAt: "2024-04-30 23:59:59", want: false},
+		{createdAt: "2024-05-01 00:00:00", want: true},
+		{createdAt: "2024-05-01 00:30:00", want: true},
+		{createdAt: "2024-05-01 23:59:59", want: true},
+		{createdAt: "2024-05-02 00:00:00", want: false},
+		{createdAt: "2024-04-30T22:30:00Z", want: true},
+		{createdAt: "2024-05-01T22:30:00Z", want: false},
+		{createdAt: "not a time", want: true},
+	}
+	for _, tt := range tests {
+		trx := transaction("trx", efashe.TransactionSuccessedState, 100, tt.createdAt)
+		if got := inPeriod(trx, from, to); got != tt.want {
+			t.Errorf("inPeriod(%s) = %v, want %v", tt.createdAt, got, tt.want)
+		}
+		// The same period expressed in UTC.
+		if got := inPeriod(trx, from.UTC(), to.UTC()); got != tt.want {
+			t.Errorf("inPeriod(%s) in UTC = %v, want %v", tt.createdAt, got, tt.want)
+		}
+	}
+}
+
+func TestReconcile(t *testing.T) {
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, kigali)
+	to := from.AddDate(0, 0, 1)
+	cl := &testClient{
+		trxs: map[string]efashe.Transaction{
+			"matched":  transaction("matched", efashe.TransactionSuccessedState, 100, "2024-05-01 10:00:00"),
+			"amount":   transaction("amount", efashe.TransactionSuccessedState, 150, "2024-05-01 10:00:00"),
+			"failed":   transaction("failed", efashe.TransactionFailedState, 100, "2024-05-01 10:00:00"),
+			"pending":  transaction("pending", efashe.TransactionPendingState, 100, "2024-05-01 10:00:00"),
+			"accounts": {TransactionId: "accounts", TransactionStatusId: efashe.TransactionSuccessedState, Amount: 100, CustomerAccountNumber: "0790000000"},
+		},
+		history: []efashe.TransactionRecord{
+			{Transaction: transaction("matched", efashe.TransactionSuccessedState, 100, "2024-05-01 10:00:00")},
+			{Transaction: transaction("local-missing", efashe.TransactionSuccessedState, 100, "2024-05-01 00:10:00")},
+			{Transaction: transaction("failed-missing", efashe.TransactionFailedState, 100, "2024-05-01 10:00:00")},
+			// Listed as the history is filtered on whole days, but out of the period.
+			{Transaction: transaction("before", efashe.TransactionSuccessedState, 100, "2024-04-30 23:50:00")},
+			{Transaction: transaction("after", efashe.TransactionSuccessedState, 100, "2024-05-02 00:10:00")},
+		},
+	}
+	records := []Record{
+		{TransactionId: "matched", Amount: 100, CustomerAccountNumber: "0780000000"},
+		{TransactionId: "amount", Amount: 100},
+		{TransactionId: "failed", Amount: 100},
+		{TransactionId: "pending", Amount: 100},
+		{TransactionId: "accounts", Amount: 100, CustomerAccountNumber: "0780000000"},
+		{TransactionId: "unknown", Amount: 100},
+	}
+
+	report, err := New(cl).Reconcile(context.Background(), records, from.UTC(), to.UTC())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Status{
+		"matched":       Matched,
+		"amount":        AmountMismatch,
+		"failed":        FailedUpstream,
+		"pending":       PendingUpstream,
+		"accounts":      AccountMismatch,
+		"unknown":       MissingUpstream,
+		"local-missing": MissingLocal,
+	}
+	if len(report.Items) != len(want) {
+		t.Fatalf("report has %d items, want %d: %+v", len(report.Items), len(want), report.Items)
+	}
+	for _, it := range report.Items {
+		if it.Status != want[it.TransactionId] {
+			t.Errorf("%s status = %s, want %s", it.TransactionId, it.Status, want[it.TransactionId])
+		}
+	}
+	if n := report.Summary()[MissingLocal]; n != 1 {
+		t.Errorf("Summary() has %d missing local items, want 1", n)
+	}
+	if !cl.filter.From.Equal(from) || !cl.filter.To.Equal(to) {
+		t.Errorf("history filtered from %s to %s, want from %s to %s", cl.filter.From, cl.filter.To, from, to)
+	}
+}
@@ -0,0 +1,175 @@
+package efashevdsapigo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Default allowed gap between a transaction timestamp and its electricity token timestamp when matching by amount.
+const DefaultTokenMatchTolerance = 15 * time.Minute
+
+var timestampLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+}
+
+// Delivers electricity tokens to customers, e.g through an SMS gateway or a mail server.
+type TokenSender interface {
+	SendToken(ctx context.Context, deliveryMethodId, deliverTo string, token ElectricityToken) error
+}
+
+// Find the token bought by the transaction.
+// Tokens are matched by the service provider receipt number, when the transaction has none
+// they are matched by amount and the closest timestamp within the tolerance.
+func MatchElectricityToken(tokens []ElectricityToken, trx Transaction, tolerance time.Duration) (*ElectricityToken, error) {
+
+	if receiptNo := strings.TrimSpace(trx.SpVendInfo.ReceiptNo); receiptNo != "" {
+		for _, t := range tokens {
+			if strings.TrimSpace(t.ReceiptNo) == receiptNo {
+				return &t, nil
+			}
+		}
+		return nil, ErrTokenNotFound
+	}
+
+	tstamp, ok := parseTimestamp(trx.SpVendInfo.Tstamp)
+	if !ok {
+		tstamp, ok = parseTimestamp(trx.CreatedAt)
+	}
+
+	var (
+		match   *ElectricityToken
+		minGap  = time.Duration(math.MaxInt64)
+		matches int
+	)
+	for _, t := range tokens {
+		if math.Abs(t.Amount-trx.Amount) >= 0.005 {
+			continue
+		}
+		matches++
+		if !ok {
+			match = &t
+			continue
+		}
+		tt, tok := parseTimestamp(t.Tstamp)
+		if !tok {
+			continue
+		}
+		gap := tt.Sub(tstamp).Abs()
+		if gap <= tolerance && gap < minGap {
+			match, minGap = &t, gap
+		}
+	}
+	// Without timestamps an amount only match is ambiguous unless it is unique.
+	if match == nil || (!ok && matches > 1) {
+		return nil, ErrTokenNotFound
+	}
+	return match, nil
+}
+
+// Fetch the latest tokens of the transaction meter and find the one bought by the transaction.
+func RecoverElectricityToken(ctx context.Context, cl Client, trx Transaction, opts ...Option) (*ElectricityToken, error) {
+
+	res, err := cl.ElectricityTokens(ctx, trx.CustomerAccountNumber, MaxElectricityTokensCount, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return MatchElectricityToken(res.Data, trx, DefaultTokenMatchTolerance)
+}
+
+// Re-deliver the latest token of the meter by sms or email for customers who lost it.
+func ResendLastElectricityToken(ctx context.Context, cl Client, sender TokenSender, meterNo, deliveryMethodId, deliverTo string, opts ...Option) (*ElectricityToken, error) {
+
+	switch deliveryMethodId {
+	case SmsDeliveryMethodId:
+		if !isPhoneNumber(deliverTo) {
+			return nil, ValidationError(fmt.Sprintf("invalid phone number %q to deliver to", deliverTo))
+		}
+	case EmailDeliveryMethodId:
+		if !isEmail(deliverTo) {
+			return nil, ValidationError(fmt.Sprintf("invalid email %q to deliver to", deliverTo))
+		}
+	default:
+		return nil, ValidationError(fmt.Sprintf("tokens can only be resent by %s or %s", SmsDeliveryMethodId, EmailDeliveryMethodId))
+	}
+
+	res, err := cl.ElectricityTokens(ctx, meterNo, MaxElectricityTokensCount, opts...)
+	if err != nil {
+		return nil, err
+	}
+	last, err := LastElectricityToken(res.Data)
+	if err != nil {
+		return nil, err
+	}
+	err = sender.SendToken(ctx, deliveryMethodId, deliverTo, *last)
+	if err != nil {
+		return nil, err
+	}
+	return last, nil
+}
+
+// The most recent token by timestamp, the first listed token is returned when no timestamp can be parsed.
+func LastElectricityToken(tokens []ElectricityToken) (*ElectricityToken, error) {
+
+	if len(tokens) == 0 {
+		return nil, ErrTokenNotFound
+	}
+	var (
+		last   = tokens[0]
+		latest time.Time
+	)
+	for _, t := range tokens {
+		tt, ok := parseTimestamp(t.Tstamp)
+		if ok && tt.After(latest) {
+			last, latest = t, tt
+		}
+	}
+	return &last, nil
+}
+
+// Kigali time, the API formats its timestamps without zone in it. Rwanda has no daylight saving,
+// a fixed UTC+2 zone is used when the time zone database is missing.
+var defaultTimestampLocation = func() *time.Location {
+
+	loc, err := time.LoadLocation("Africa/Kigali")
+	if err != nil {
+		return time.FixedZone("CAT", 2*60*60)
+	}
+	return loc
+}()
+
+var timestampLocation atomic.Pointer[time.Location]
+
+// Set the location of the API timestamps without zone and of the history filter dates, Africa/Kigali by default.
+// A nil location restores the default.
+func SetTimestampLocation(loc *time.Location) {
+	timestampLocation.Store(loc)
+}
+
+// The location set by SetTimestampLocation.
+func TimestampLocation() *time.Location {
+
+	if loc := timestampLocation.Load(); loc != nil {
+		return loc
+	}
+	return defaultTimestampLocation
+}
+
+func parseTimestamp(v string) (time.Time, bool) {
+
+	v = strings.TrimSpace(v)
+	loc := TimestampLocation()
+	for _, layout := range timestampLayouts {
+		t, err := time.ParseInLocation(layout, v, loc)
+		if err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
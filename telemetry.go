@@ -0,0 +1,262 @@
+package efashevdsapigo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/quarksgroup/efashe-vds-api-go"
+
+// Attribute keys of spans and metrics, secrets and tokens are never recorded.
+const (
+	EndpointAttributeKey      = attribute.Key("efashe.endpoint")
+	TransactionIdAttributeKey = attribute.Key("efashe.trx_id")
+	VerticalIdAttributeKey    = attribute.Key("efashe.vertical_id")
+	ErrorAttributeKey         = attribute.Key("efashe.error")
+	StateAttributeKey         = attribute.Key("efashe.trx_state")
+	TokenRefreshAttributeKey  = attribute.Key("efashe.token_refresh")
+)
+
+// Sentinel errors reported by the error counter, other errors are counted as "other".
+var errorNames = []struct {
+	err  error
+	name string
+}{
+	{ErrAPIDown, "api_down"},
+	{ErrRateLimited, "rate_limited"},
+	{ErrUnauthorized, "unauthorized"},
+	{ErrAccountBlocked, "account_blocked"},
+	{ErrAccountNotFound, "account_not_found"},
+	{ErrTransactionNotFound, "transaction_not_found"},
+	{ErrProductOutOfStock, "product_out_of_stock"},
+	{ErrInsufficientBalance, "insufficient_balance"},
+	{ErrAmbiguousExecution, "ambiguous_execution"},
+	{ErrTransactionInFlight, "transaction_in_flight"},
+	{ErrAgencyInactive, "agency_inactive"},
+	{ErrAgencySuspended, "agency_suspended"},
+	{ErrAgencyBlacklisted, "agency_blacklisted"},
+	{ErrKYCPending, "kyc_pending"},
+	{ErrBranchInactive, "branch_inactive"},
+	{context.DeadlineExceeded, "deadline_exceeded"},
+	{context.Canceled, "canceled"},
+}
+
+type telemetry struct {
+	tracer       trace.Tracer
+	propagator   propagation.TextMapPropagator
+	duration     metric.Float64Histogram
+	errors       metric.Int64Counter
+	refreshes    metric.Int64Counter
+	statusChecks metric.Int64Counter
+	vendOutcomes metric.Int64Counter
+
+	executedMu sync.Mutex
+	// Ids of the transactions executed by the client whose final state was not seen yet, by execution time.
+	executed map[string]time.Time
+	pruned   time.Time
+}
+
+// The vend outcome of an execution whose acceptance is unknown.
+const ambiguousVendOutcome = "ambiguous"
+
+// How long an executed transaction waits for its final status to be counted, so transactions never polled are dropped.
+const executedTTL = 24 * time.Hour
+
+func newTelemetry(tp trace.TracerProvider, mp metric.MeterProvider) (*telemetry, error) {
+
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	if mp == nil {
+		mp = otel.GetMeterProvider()
+	}
+	meter := mp.Meter(instrumentationName)
+
+	t := &telemetry{
+		tracer:     tp.Tracer(instrumentationName),
+		propagator: otel.GetTextMapPropagator(),
+		executed:   map[string]time.Time{},
+	}
+	var err, e error
+	t.duration, e = meter.Float64Histogram("efashe.client.request.duration",
+		metric.WithDescription("Duration of HTTP requests to the Efashe API."), metric.WithUnit("s"))
+	err = errors.Join(err, e)
+	t.errors, e = meter.Int64Counter("efashe.client.errors",
+		metric.WithDescription("API call errors by sentinel error."))
+	err = errors.Join(err, e)
+	t.refreshes, e = meter.Int64Counter("efashe.client.token.refreshes",
+		metric.WithDescription("Access token renewals, with a refresh token or a fresh authentication."))
+	err = errors.Join(err, e)
+	t.statusChecks, e = meter.Int64Counter("efashe.client.vend.status_checks",
+		metric.WithDescription("Vend transaction status lookups by the state returned."))
+	err = errors.Join(err, e)
+	t.vendOutcomes, e = meter.Int64Counter("efashe.client.vend.outcomes",
+		metric.WithDescription("Vend executions by outcome: failed or ambiguous when VendExecute returns, otherwise the final state once first seen."))
+	err = errors.Join(err, e)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Identifies an API call for instrumentation.
+type callInfo struct {
+	endpoint      string
+	transactionId string
+	verticalId    string
+}
+
+type callInfoKey struct{}
+
+// The call info set by startCall.
+func callInfoFrom(ctx context.Context) (callInfo, bool) {
+	info, ok := ctx.Value(callInfoKey{}).(callInfo)
+	return info, ok
+}
+
+// Start an API call with its span and hooks, the returned func ends it with the call outcome.
+func (c *client) startCall(ctx context.Context, call callInfo, opts ...Option) (context.Context, func(res any, err error)) {
+
+	ctx = context.WithValue(ctx, callInfoKey{}, call)
+	// Set even when nil so nested calls, e.g the authentication, do not run the hooks of their parent.
+	hooks := c.callHooks(opts)
+	ctx = context.WithValue(ctx, callHooksKey{}, hooks)
+	endSpan := func(any, error) {}
+	if c.telemetry != nil {
+		ctx, endSpan = c.startSpan(ctx, call)
+	}
+	return ctx, func(res any, err error) {
+		hooks.response(ctx, res, err)
+		endSpan(res, err)
+	}
+}
+
+func (c *client) startSpan(ctx context.Context, call callInfo) (context.Context, func(res any, err error)) {
+
+	attrs := []attribute.KeyValue{EndpointAttributeKey.String(call.endpoint)}
+	if call.transactionId != "" {
+		attrs = append(attrs, TransactionIdAttributeKey.String(call.transactionId))
+	}
+	if call.verticalId != "" {
+		attrs = append(attrs, VerticalIdAttributeKey.String(call.verticalId))
+	}
+	ctx, span := c.telemetry.tracer.Start(ctx, "efashe "+call.endpoint,
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+
+	return ctx, func(res any, err error) {
+		defer span.End()
+		if err != nil {
+			name := errorName(err)
+			span.SetStatus(codes.Error, name)
+			span.SetAttributes(ErrorAttributeKey.String(name))
+			c.telemetry.errors.Add(ctx, 1, metric.WithAttributes(EndpointAttributeKey.String(call.endpoint), ErrorAttributeKey.String(name)))
+			return
+		}
+		switch res := res.(type) {
+		case *VendValidateResp:
+			span.SetAttributes(TransactionIdAttributeKey.String(res.Data.TransactionId))
+		case *VendTransactionStatusResp:
+			state := res.Data.TransactionStatusId
+			span.SetAttributes(StateAttributeKey.String(state))
+			c.telemetry.statusChecks.Add(ctx, 1, metric.WithAttributes(StateAttributeKey.String(state)))
+			if IsTerminalState(state) {
+				if c.telemetry.untrackExecuted(call.transactionId) {
+					c.telemetry.vendOutcomes.Add(ctx, 1, metric.WithAttributes(StateAttributeKey.String(state)))
+				}
+			}
+		}
+	}
+}
+
+// Track a transaction executed by the client until its final state is seen, expired ones are pruned at most hourly.
+func (t *telemetry) trackExecuted(transactionId string) {
+
+	t.executedMu.Lock()
+	defer t.executedMu.Unlock()
+	now := time.Now()
+	if now.Sub(t.pruned) >= time.Hour {
+		for id, at := range t.executed {
+			if now.Sub(at) >= executedTTL {
+				delete(t.executed, id)
+			}
+		}
+		t.pruned = now
+	}
+	t.executed[transactionId] = now
+}
+
+// Forget a transaction executed by the client, false is returned when it was not tracked or has expired.
+func (t *telemetry) untrackExecuted(transactionId string) bool {
+
+	t.executedMu.Lock()
+	defer t.executedMu.Unlock()
+	at, ok := t.executed[transactionId]
+	delete(t.executed, transactionId)
+	return ok && time.Since(at) < executedTTL
+}
+
+// Record the outcome of VendExecute, a successful execution is counted once its final state is seen.
+func (c *client) recordVendExecute(ctx context.Context, transactionId string, err error, definite bool) {
+
+	if c.telemetry == nil {
+		return
+	}
+	switch {
+	case err == nil:
+		c.telemetry.trackExecuted(transactionId)
+	case definite:
+		c.telemetry.vendOutcomes.Add(ctx, 1, metric.WithAttributes(StateAttributeKey.String(TransactionFailedState)))
+	case !errors.Is(err, ErrTransactionInFlight):
+		c.telemetry.vendOutcomes.Add(ctx, 1, metric.WithAttributes(StateAttributeKey.String(ambiguousVendOutcome)))
+	}
+}
+
+// Record an HTTP request on the call span and the duration histogram.
+func (t *telemetry) recordRequest(ctx context.Context, endpoint, method string, statusCode int, d time.Duration) {
+
+	attrs := []attribute.KeyValue{
+		EndpointAttributeKey.String(endpoint),
+		attribute.String("http.request.method", method),
+	}
+	if statusCode != 0 {
+		attrs = append(attrs, attribute.Int("http.response.status_code", statusCode))
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attrs...)
+	t.duration.Record(ctx, d.Seconds(), metric.WithAttributes(attrs...))
+}
+
+// Record a token renewal, kind is either refresh or auth.
+func (c *client) recordTokenRefresh(ctx context.Context, kind string) {
+
+	if c.telemetry != nil {
+		c.telemetry.refreshes.Add(ctx, 1, metric.WithAttributes(TokenRefreshAttributeKey.String(kind)))
+	}
+}
+
+func (t *telemetry) inject(ctx context.Context, h http.Header) {
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(h))
+}
+
+func errorName(err error) string {
+
+	for _, e := range errorNames {
+		if errors.Is(err, e.err) {
+			return e.name
+		}
+	}
+	var vErr ValidationError
+	if errors.As(err, &vErr) {
+		return "validation"
+	}
+	return "other"
+}
@@ -0,0 +1,101 @@
+package efashevdsapigo
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// STS tokens are 20 digits carrying a 66 bits number.
+const stsTokenLength = 20
+
+var stsTokenMax = new(big.Int).Lsh(big.NewInt(1), 66)
+
+// Units and charges of an electricity token, ready for display.
+type ElectricityTokenBreakdown struct {
+	Units  float64
+	Amount float64
+	Vat    float64
+	// Regulatory fees levied on the purchase.
+	RegulatoryFees float64
+	// The amount which bought the units, once VAT and regulatory fees are deducted.
+	NetAmount float64
+	// The price of one unit, zero when no units were bought.
+	UnitPrice float64
+}
+
+// Format a token in groups of 4 digits, tokens with characters other than digits, spaces and dashes are returned trimmed.
+func FormatToken(token string) string {
+
+	digits := tokenDigits(token)
+	if !isDigits(digits) {
+		return strings.TrimSpace(token)
+	}
+	var groups []string
+	for len(digits) > 4 {
+		groups = append(groups, digits[:4])
+		digits = digits[4:]
+	}
+	return strings.Join(append(groups, digits), " ")
+}
+
+// Check a token is a 20 digits STS token, spaces and dashes are ignored.
+// The token CRC is encrypted with the meter key so only the length and the 66 bits range can be checked.
+func ValidateToken(token string) error {
+
+	digits := tokenDigits(token)
+	if len(digits) != stsTokenLength || !isDigits(digits) {
+		return ValidationError(fmt.Sprintf("token %q is not %d digits", token, stsTokenLength))
+	}
+	n, _ := new(big.Int).SetString(digits, 10)
+	if n.Cmp(stsTokenMax) >= 0 {
+		return ValidationError(fmt.Sprintf("token %q is out of the STS range", token))
+	}
+	return nil
+}
+
+// The non-empty tokens of the record.
+func (t ElectricityToken) Tokens() []string {
+
+	var res []string
+	for _, v := range []string{t.Token, t.Token2, t.Token3} {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// The non-empty tokens of the record grouped by 4 digits.
+func (t ElectricityToken) FormattedTokens() []string {
+
+	res := t.Tokens()
+	for i, v := range res {
+		res[i] = FormatToken(v)
+	}
+	return res
+}
+
+func (t ElectricityToken) Breakdown() ElectricityTokenBreakdown {
+
+	b := ElectricityTokenBreakdown{
+		Units:          t.Units,
+		Amount:         t.Amount,
+		Vat:            t.Vat,
+		RegulatoryFees: t.RegulatoryFees,
+		NetAmount:      t.Amount - t.Vat - t.RegulatoryFees,
+	}
+	if t.Units > 0 {
+		b.UnitPrice = b.NetAmount / t.Units
+	}
+	return b
+}
+
+// The voucher grouped by 4 digits when it is a token.
+func (v SPVendInfo) FormattedVoucher() string {
+	return FormatToken(v.Voucher)
+}
+
+func tokenDigits(token string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(token))
+}
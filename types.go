@@ -3,11 +3,14 @@ package efashevdsapigo
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 const (
 	APIV2BaseURL = "https://sb-api.efashe.com/rw/v2/"
 
+	MaxElectricityTokensCount = 10
+
 	// Known balances id
 	CommissionBalanceId = "commission"
 	MainBalanceId       = "main"
@@ -17,6 +20,36 @@ const (
 	AirtimeVerticalId     = "airtime"
 	PayTvVerticalId       = "paytv"
 	ElectricityVerticalId = "electricity"
+	TaxVerticalId         = "tax"
+	WaterVerticalId       = "water"
+
+	// Vertical status
+	VerticalActiveStatus   = "active"
+	VerticalInactiveStatus = "inactive"
+
+	// Agency status
+	AgencyActiveStatus      = "active"
+	AgencyInactiveStatus    = "inactive"
+	AgencySuspendedStatus   = "suspended"
+	AgencyBlacklistedStatus = "blacklisted"
+	AgencyKYCPendingStatus  = "kyc_pending"
+
+	// API status
+	APIOperationalStatus   = "operational"
+	APIDegradedStatus      = "degraded"
+	APIPartialOutageStatus = "partial_outage"
+	APIMajorOutageStatus   = "major_outage"
+	APIMaintenanceStatus   = "maintenance"
+
+	// Vend unit id
+	FixedVendUnitId    = "fixed"
+	FlexibleVendUnitId = "flexible"
+
+	// Known delivery methods id
+	PrintDeliveryMethodId       = "print"
+	EmailDeliveryMethodId       = "email"
+	SmsDeliveryMethodId         = "sms"
+	DirectTopupDeliveryMethodId = "direct_topup"
 
 	// Transaction state
 	TransactionFailedState    = "failed"
@@ -34,6 +67,19 @@ var (
 	ErrProductOutOfStock   = errors.New("product is out of stock")
 	ErrInsufficientBalance = errors.New("insufficient wallet balance")
 	ErrAPIDown             = errors.New("API is down")
+	ErrVerticalNotFound    = errors.New("vertical not found")
+	ErrAmountBelowMin      = errors.New("amount is below the minimum vend amount")
+	ErrAmountAboveMax      = errors.New("amount is above the maximum vend amount")
+	ErrAmountNotSelectable = errors.New("amount is not one of the product denominations")
+	ErrNoExtraInfo         = errors.New("no extra info for this vertical")
+	ErrTokenNotFound       = errors.New("electricity token not found")
+	ErrNoCommissionRates   = errors.New("no commission rates for this product")
+	ErrBranchNotFound      = errors.New("branch not found in pool")
+	ErrAgencyInactive      = errors.New("agency account is inactive")
+	ErrAgencySuspended     = errors.New("agency account is suspended")
+	ErrAgencyBlacklisted   = errors.New("agency account is blacklisted")
+	ErrKYCPending          = errors.New("agency account KYC is pending")
+	ErrBranchInactive      = errors.New("agency branch is not active")
 )
 
 type Client interface {
@@ -42,6 +88,8 @@ type Client interface {
 	InitAuth(ctx context.Context, opts ...Option) error
 	// Check if API gateway is up.
 	Status(ctx context.Context, opts ...Option) (*StatusResp, error)
+	// The agency and branch the client is authenticated as, it is refreshed on every fresh authentication.
+	Profile() Profile
 	// Calls auth endpoint and returns the auth details.
 	// It does not update the client tokens.
 	Auth(ctx context.Context, opts ...Option) (*AuthResp, error)
@@ -55,15 +103,26 @@ type Client interface {
 	// List available services.
 	ListVerticals(ctx context.Context, opts ...Option) (*ListVerticalsResp, error)
 	// Validate vend operation. It is called before carrying a transaction to get the transaction Id.
+	// With WithAccountNumberValidationOption the customer account number is checked and normalized with the validator
+	// registered for the vertical before sending.
 	VendValidate(ctx context.Context, body VendValidateBody, opts ...Option) (*VendValidateResp, error)
 	// Execute a transaction.
+	// The body is validated before sending, use VendExecuteBody.ValidateAgainst to also check it against the vend validate response.
+	// With WithAccountNumberValidationOption the customer account number is normalized as VendValidate does.
+	// With WithIdempotencyStoreOption a transaction id is executed at most once.
 	VendExecute(ctx context.Context, body VendExecuteBody, opts ...Option) (*VendExecuteResp, error)
 	// Reports the status of a vend transaction.
 	VendTransactionStatus(ctx context.Context, transactionId string, opts ...Option) (*VendTransactionStatusResp, error)
 	// Initiate a new transaction using a previous transaction from history.
 	RepeatTransaction(ctx context.Context, transactionId string, opts ...Option) (*VendExecuteResp, error)
 	// Get latest tokens of the meter number.
+	// The tokens count must be between 1 and MaxElectricityTokensCount, zero gets the maximum.
 	ElectricityTokens(ctx context.Context, meterNo string, tokensCount int, opts ...Option) (*ElectricityTokenResp, error)
+	// List a page of the transactions history matching the filter.
+	// Use AllTransactions to walk through all pages.
+	ListTransactions(ctx context.Context, filter TransactionFilter, opts ...Option) (*ListTransactionsResp, error)
+	// Get the full historical record of a transaction.
+	GetTransaction(ctx context.Context, transactionId string, opts ...Option) (*GetTransactionResp, error)
 }
 
 type Option interface {
@@ -81,90 +140,13 @@ func (v ValidationError) Error() string {
 }
 
 type VendTransactionStatusResp struct {
-	Data struct {
-		// transaction ID
-		TransactionId string `json:"trxId"`
-		// successful┃failed┃initiated┃pending┃timedout
-		TransactionStatusId string `json:"trxStatusId"`
-		// The customer account number i.e topup mobile number, electricity meterno, paytv decoder number etc.
-		CustomerAccountNumber string `json:"customerAccountNumber"`
-		// Where the Service Provider platform returns the customer account name, this field will bear it. Else, it will be empty
-		CustomerAccountName string `json:"customerAccountName"`
-		// The trx record creation timestamp on the Efashe platform
-		CreatedAt string `json:"createdAt"`
-		// The timestamp indicating when the trx record was last updated on the Efashe platforms.
-		UpdatedAt string `json:"updatedAt"`
-		// The amount tendered for the trx
-		Amount float64 `json:"amount"`
-		// The relevant currency code
-		Currency string `json:"currency"`
-
-		// The Service Provider Vend Information.
-		// SP means MTN, Airtel, EUCL e.t.c...
-		SpVendInfo struct {
-			//  The Service Provider's Tax Identification Number (TIN)
-			TIN string `json:"tin"`
-			// The Service Provider's VAT number if provided.
-			VatNo string `json:"vatNo"`
-			// The timestamp returned by the Service Provider
-			Tstamp string `json:"tstamp"`
-			// The name of the Service Provider
-			SpName string `json:"spName"`
-			// This is the Service Provider's receipt number for products such as Electricity.
-			ReceiptNo string `json:"receiptNo"`
-			// Where the product is voucher based e.g Prepaid Electricity or Airtime Vouchers; this param bears the returned voucher.
-			Voucher string `json:"voucher"`
-			// Where relevant, this will bear the units of purchase.
-			// E.g for prepaid electricity, these will be the actual electricity units (like 2.4 kwh), while for airtime, this will typically indicate the qty of the denomination bought.
-			Units      string  `json:"units"`
-			UnitsWorth float64 `json:"unitsWorth"`
-			//  The amount posted to the Service Provider's platform for the trx
-			TransactionAmount float64      `json:"trxAmount"`
-			Deductions        []Deductions `json:"deductions"`
-		} `json:"spVendInfo"`
-
-		// May be our information. Agency might be referring to us.
-		OurVendInfo struct {
-			// The agency Tax Identification number. Where not available, this field will be blank.
-			OurTIN        string       `json:"ourTIN"`
-			OurDeductions []Deductions `json:"ourDeductions"`
-			// The business name associated with the agency account
-			AgencyName string `json:"agencyName"`
-			// The Point of Presence (Branch) user friendly name. The default Branch is automatically named Main/HQ.
-			BranchName string `json:"branchName"`
-			// The Branch shortcode
-			BranchShortCode string `json:"branchShortCode"`
-			// The name of the staff who triggered the trx
-			StaffName string `json:"staffName"`
-			Narrative string `json:"narrative"`
-		} `json:"ourVendInfo"`
-	} `json:"data"`
+	Data Transaction `json:"data"`
 }
 
 type AuthResp struct {
 	Data struct {
-		AgencyBranch struct {
-			BranchId        string `json:"branchId"`
-			BranchName      string `json:"branchName"`
-			BranchShortCode string `json:"branchShortCode"`
-			BranchStatusId  string `json:"branchStatusId"`
-			// Allowed: fixed┃roaming┃virtual
-			PresenceId string `json:"presenceId"`
-			// Allowed: main┃branch
-			ClassId       string `json:"classId"`
-			Address1Id    string `json:"address1Id"`
-			Address2Id    string `json:"address2Id"`
-			StreetAddress string `json:"streetAddress"`
-		} `json:"agencyBranch"`
-		AgencyAccount struct {
-			AgencyId        string `json:"agencyId"`
-			AgencyName      string `json:"agencyName"`
-			AgencyShortCode string `json:"agencyShortCode"`
-			// Allowed: L1┃L2┃L3
-			AgencyLevelId string `json:"agencyLevelId"`
-			// Allowed: active┃inactive┃suspended┃blacklisted┃kyc_pending
-			AgencyStatusId string `json:"agencyStatusId"`
-		} `json:"agencyAccount"`
+		AgencyBranch  AgencyBranch  `json:"agencyBranch"`
+		AgencyAccount AgencyAccount `json:"agencyAccount"`
 		// The JWT to use to access protected enpoints
 		AccessToken string `json:"accessToken"`
 		// The JWT Refresh Token used to generate a new accessToken
@@ -195,55 +177,7 @@ type ListVerticalsResp struct {
 }
 
 type VendValidateResp struct {
-	Data struct {
-		// e.g: electricity-eucl-rw, airtime-mtn-rw...
-		PdtId string `json:"pdtId"`
-		// e.g: EUCL Prepaid Electricity
-		PdtName string `json:"pdtName"`
-		// may be among active┃inactive┃suspended...
-		PdtStatusId string `json:"pdtStatusId"`
-		SharedVendInfo
-		CustomerAccountName string `json:"customerAccountName"`
-		// e.g: EUCL
-		ServiceProviderName string `json:"svcProviderName"`
-		// 	Allowed: fixed┃flexible
-		// This param defines the vending model of the product or service as below:
-		//
-		// fixed - this means that only fixed amounts set by the Service Provider (SP) can be accepted. This is typical of Airtime vouchers and subscription package based services. When set, the integrated frontend should disable arbitrary input of amounts and only allow the selection of fixed denominations.
-		// flexible - this means that the customer can tender any amount between the defined vendMin and vendMax amount. When set, the integrated frontend can allow for arbitrary input of amounts within the accepted vending range or a selection of amount from the selectAmount list
-		VendUnitId string `json:"vendUnitId"`
-		// This is the minimum vend amount that can be accepted for the trx
-		VendMin float64 `json:"vendMin"`
-		// This is the upper limit of the vend transaction amount. All amounts greater than this value will be rejected by the API.
-		VendMax float64 `json:"vendMax"`
-
-		// This is the trxId to use when calling the /vend/execute endpoint. This is required for idempotent processing of the transaction.
-		TransactionId string `json:"trxId"`
-		//	Allowed: voucher┃direct_topup
-		//
-		// voucher - this means the vend transaction results or returns a voucher
-		// direct_topup - this means the vend transaction results in the direct topup of the customer's service account
-		TransactionResult string `json:"trxResult"`
-		// 	This shows the available wallet balance for the transaction. It is usually the sum of:
-		//
-		// main.AvailBal + refund.AvailBal - where commission auto depletion is disabled
-		// main.AvailBal + refund.AvailBal + commission.AvailBal - where business policy allows for automatic depletion of the commission wallet.
-		AvailTransactionBalance float64                  `json:"availTrxBalance"`
-		DeliveryMethods         []VerticalDeliveryMethod `json:"deliveryMethods"`
-		// Optional fixed amounts for selection when vendUnitId is flexible. Null when not applicable.
-		SelectAmount []struct {
-			Amount   float64 `json:"amount"`
-			Currency string  `json:"currency"`
-		} `json:"selectAmount,omitempty"`
-		// Stock management flag; structure varies by product. Null when not applicable.
-		LocalStockMgt any `json:"localStockMgt,omitempty"`
-		// Stocked products list; structure varies by product. Null when not applicable.
-		StockedPdts any `json:"stockedPdts,omitempty"`
-		// Stock quantity or info; structure varies by product. Null when not applicable.
-		Stock any `json:"stock,omitempty"`
-		// Vertical-specific metadata (e.g. tax: tin, validate_id, pay_ref, tax_center, dec_date, is_full_pay, tax_type).
-		ExtraInfo map[string]any `json:"extraInfo,omitempty"`
-	} `json:"data"`
+	Data VendValidation `json:"data"`
 }
 
 type VendExecuteResp struct {
@@ -260,6 +194,15 @@ type ElectricityTokenResp struct {
 	Data []ElectricityToken `json:"data"`
 }
 
+type ListTransactionsResp struct {
+	Data       []TransactionRecord `json:"data"`
+	Pagination Pagination          `json:"pagination"`
+}
+
+type GetTransactionResp struct {
+	Data TransactionRecord `json:"data"`
+}
+
 type StatusResp struct {
 	// Allowed: operational┃degraded┃partial_outage┃major_outage┃maintenance Status of the API.
 	Status string `json:"status"`
@@ -286,6 +229,152 @@ type VendExecuteBody struct {
 	CallBack string `json:"callBack"`
 }
 
+type Transaction struct {
+	// transaction ID
+	TransactionId string `json:"trxId"`
+	// successful┃failed┃initiated┃pending┃timedout
+	TransactionStatusId string `json:"trxStatusId"`
+	// The customer account number i.e topup mobile number, electricity meterno, paytv decoder number etc.
+	CustomerAccountNumber string `json:"customerAccountNumber"`
+	// Where the Service Provider platform returns the customer account name, this field will bear it. Else, it will be empty
+	CustomerAccountName string `json:"customerAccountName"`
+	// The trx record creation timestamp on the Efashe platform
+	CreatedAt string `json:"createdAt"`
+	// The timestamp indicating when the trx record was last updated on the Efashe platforms.
+	UpdatedAt string `json:"updatedAt"`
+	// The amount tendered for the trx
+	Amount float64 `json:"amount"`
+	// The relevant currency code
+	Currency string `json:"currency"`
+
+	// The Service Provider Vend Information.
+	// SP means MTN, Airtel, EUCL e.t.c...
+	SpVendInfo SPVendInfo `json:"spVendInfo"`
+	// May be our information. Agency might be referring to us.
+	OurVendInfo AgencyVendInfo `json:"ourVendInfo"`
+}
+
+type Profile struct {
+	AgencyAccount AgencyAccount
+	AgencyBranch  AgencyBranch
+}
+
+type AgencyBranch struct {
+	BranchId        string `json:"branchId"`
+	BranchName      string `json:"branchName"`
+	BranchShortCode string `json:"branchShortCode"`
+	BranchStatusId  string `json:"branchStatusId"`
+	// Allowed: fixed┃roaming┃virtual
+	PresenceId string `json:"presenceId"`
+	// Allowed: main┃branch
+	ClassId       string `json:"classId"`
+	Address1Id    string `json:"address1Id"`
+	Address2Id    string `json:"address2Id"`
+	StreetAddress string `json:"streetAddress"`
+}
+
+type AgencyAccount struct {
+	AgencyId        string `json:"agencyId"`
+	AgencyName      string `json:"agencyName"`
+	AgencyShortCode string `json:"agencyShortCode"`
+	// Allowed: L1┃L2┃L3
+	AgencyLevelId string `json:"agencyLevelId"`
+	// Allowed: active┃inactive┃suspended┃blacklisted┃kyc_pending
+	AgencyStatusId string `json:"agencyStatusId"`
+}
+
+type VendValidation struct {
+	// e.g: electricity-eucl-rw, airtime-mtn-rw...
+	PdtId string `json:"pdtId"`
+	// e.g: EUCL Prepaid Electricity
+	PdtName string `json:"pdtName"`
+	// may be among active┃inactive┃suspended...
+	PdtStatusId string `json:"pdtStatusId"`
+	SharedVendInfo
+	CustomerAccountName string `json:"customerAccountName"`
+	// e.g: EUCL
+	ServiceProviderName string `json:"svcProviderName"`
+	// 	Allowed: fixed┃flexible
+	// This param defines the vending model of the product or service as below:
+	//
+	// fixed - this means that only fixed amounts set by the Service Provider (SP) can be accepted. This is typical of Airtime vouchers and subscription package based services. When set, the integrated frontend should disable arbitrary input of amounts and only allow the selection of fixed denominations.
+	// flexible - this means that the customer can tender any amount between the defined vendMin and vendMax amount. When set, the integrated frontend can allow for arbitrary input of amounts within the accepted vending range or a selection of amount from the selectAmount list
+	VendUnitId string `json:"vendUnitId"`
+	// This is the minimum vend amount that can be accepted for the trx
+	VendMin float64 `json:"vendMin"`
+	// This is the upper limit of the vend transaction amount. All amounts greater than this value will be rejected by the API.
+	VendMax float64 `json:"vendMax"`
+
+	// This is the trxId to use when calling the /vend/execute endpoint. This is required for idempotent processing of the transaction.
+	TransactionId string `json:"trxId"`
+	//	Allowed: voucher┃direct_topup
+	//
+	// voucher - this means the vend transaction results or returns a voucher
+	// direct_topup - this means the vend transaction results in the direct topup of the customer's service account
+	TransactionResult string `json:"trxResult"`
+	// 	This shows the available wallet balance for the transaction. It is usually the sum of:
+	//
+	// main.AvailBal + refund.AvailBal - where commission auto depletion is disabled
+	// main.AvailBal + refund.AvailBal + commission.AvailBal - where business policy allows for automatic depletion of the commission wallet.
+	AvailTransactionBalance float64                  `json:"availTrxBalance"`
+	DeliveryMethods         []VerticalDeliveryMethod `json:"deliveryMethods"`
+	// Optional fixed amounts for selection when vendUnitId is flexible. Null when not applicable.
+	SelectAmount []VendAmount `json:"selectAmount,omitempty"`
+	// Stock management flag; structure varies by product. Null when not applicable.
+	LocalStockMgt any `json:"localStockMgt,omitempty"`
+	// Stocked products list; structure varies by product. Null when not applicable.
+	StockedPdts any `json:"stockedPdts,omitempty"`
+	// Stock quantity or info; structure varies by product. Null when not applicable.
+	Stock any `json:"stock,omitempty"`
+	// Vertical-specific metadata (e.g. tax: tin, validate_id, pay_ref, tax_center, dec_date, is_full_pay, tax_type), check DecodeExtraInfo.
+	ExtraInfo map[string]any `json:"extraInfo,omitempty"`
+}
+
+type VendAmount struct {
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// The Service Provider Vend Information.
+// SP means MTN, Airtel, EUCL e.t.c...
+type SPVendInfo struct {
+	//  The Service Provider's Tax Identification Number (TIN)
+	TIN string `json:"tin"`
+	// The Service Provider's VAT number if provided.
+	VatNo string `json:"vatNo"`
+	// The timestamp returned by the Service Provider
+	Tstamp string `json:"tstamp"`
+	// The name of the Service Provider
+	SpName string `json:"spName"`
+	// This is the Service Provider's receipt number for products such as Electricity.
+	ReceiptNo string `json:"receiptNo"`
+	// Where the product is voucher based e.g Prepaid Electricity or Airtime Vouchers; this param bears the returned voucher.
+	Voucher string `json:"voucher"`
+	// Where relevant, this will bear the units of purchase.
+	// E.g for prepaid electricity, these will be the actual electricity units (like 2.4 kwh), while for airtime, this will typically indicate the qty of the denomination bought.
+	Units      string  `json:"units"`
+	UnitsWorth float64 `json:"unitsWorth"`
+	//  The amount posted to the Service Provider's platform for the trx
+	TransactionAmount float64      `json:"trxAmount"`
+	Deductions        []Deductions `json:"deductions"`
+}
+
+// May be our information. Agency might be referring to us.
+type AgencyVendInfo struct {
+	// The agency Tax Identification number. Where not available, this field will be blank.
+	OurTIN        string       `json:"ourTIN"`
+	OurDeductions []Deductions `json:"ourDeductions"`
+	// The business name associated with the agency account
+	AgencyName string `json:"agencyName"`
+	// The Point of Presence (Branch) user friendly name. The default Branch is automatically named Main/HQ.
+	BranchName string `json:"branchName"`
+	// The Branch shortcode
+	BranchShortCode string `json:"branchShortCode"`
+	// The name of the staff who triggered the trx
+	StaffName string `json:"staffName"`
+	Narrative string `json:"narrative"`
+}
+
 type Balance struct {
 	Id               string  `json:"id"`
 	Name             string  `json:"name"`
@@ -334,6 +423,47 @@ type SharedVendInfo struct {
 	CustomerAccountNumber string `json:"customerAccountNumber"`
 }
 
+type TransactionFilter struct {
+	// Only transactions created from this day, ignored when zero.
+	// The upstream filters on dates, the time of day is dropped and the day is inclusive.
+	// Days are taken in the TimestampLocation.
+	From time.Time
+	// Only transactions created up to this day included, ignored when zero.
+	// The time of day is dropped, so transactions later on that day are listed too.
+	To         time.Time
+	VerticalId string
+	// successful┃failed┃initiated┃pending┃timedout
+	TransactionStatusId   string
+	CustomerAccountNumber string
+	BranchId              string
+	// Page to list starting from 1, the first page is listed when zero.
+	Page int
+	// Number of transactions per page, the upstream default is used when zero.
+	PageSize int
+}
+
+type Pagination struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"limit"`
+	Total      int `json:"total"`
+	TotalPages int `json:"totalPages"`
+}
+
+// A transaction from the history, it has the vend details of the transaction plus the product, branch and delivery.
+type TransactionRecord struct {
+	Transaction
+	VerticalId string `json:"verticalId"`
+	PdtId      string `json:"pdtId"`
+	PdtName    string `json:"pdtName"`
+	BranchId   string `json:"branchId"`
+	BranchName string `json:"branchName"`
+	StaffName  string `json:"staffName"`
+	// Allowed: print┃email┃sms┃direct_topup
+	DeliveryMethodId string `json:"deliveryMethodId"`
+	// The delivery destination of the trx receipt, empty for print and direct_topup.
+	DeliverTo string `json:"deliverTo"`
+}
+
 type GenericInfo struct {
 	Id   string `json:"id"`
 	Name string `json:"name"`
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -25,23 +26,64 @@ func addBearerToken(hds http.Header, token string) {
 	hds.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 }
 
-func httpDo(cl *http.Client, req *http.Request, jsonOut any, expectBody bool) (statusCode int, statusText string, err error) {
+func readJSON(body io.Reader, jsonOut any) error {
 
-	res, err := cl.Do(req)
+	raw, err := io.ReadAll(body)
 	if err != nil {
-		return 0, "", err
+		return err
 	}
-	defer res.Body.Close()
+	return json.Unmarshal(raw, jsonOut)
+}
+
+// Wraps an error which occurred before a request was sent, the API was not reached.
+type notSentError struct {
+	err error
+}
+
+func (e notSentError) Error() string { return e.err.Error() }
+
+func (e notSentError) Unwrap() error { return e.err }
+
+// Whether a vend execute failed for sure, either the request was not sent or the API answered it was not accepted.
+func isDefiniteFailure(statusCode int, err error) bool {
+
+	var notSent notSentError
+	if errors.As(err, &notSent) {
+		return true
+	}
+	return statusCode != 0 && statusCode < http.StatusInternalServerError
+}
 
-	if !expectBody {
-		return res.StatusCode, res.Status, nil
+// A copy of the request body, nil when it cannot be read again.
+func requestBody(req *http.Request) []byte {
+
+	if req.GetBody == nil {
+		return nil
 	}
-	body, err := io.ReadAll(res.Body)
+	body, err := req.GetBody()
 	if err != nil {
-		return 0, "", err
+		return nil
 	}
+	defer body.Close()
+	raw, _ := io.ReadAll(body)
+	return raw
+}
 
-	return res.StatusCode, res.Status, json.Unmarshal(body, jsonOut)
+// Name an endpoint by its path with ids replaced by {id}, e.g /vend/{id}/status.
+func endpointName(path string) string {
+
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 1; i < len(segs); i++ {
+		switch segs[i-1] {
+		case "vend":
+			if segs[i] != "validate" && segs[i] != "execute" {
+				segs[i] = "{id}"
+			}
+		case "history":
+			segs[i] = "{id}"
+		}
+	}
+	return "/" + strings.Join(segs, "/")
 }
 
 func parseTokenTstamp(tokenString string) (time.Time, error) {
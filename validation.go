@@ -0,0 +1,91 @@
+package efashevdsapigo
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// Check the body can be executed, the delivery destination must be an email for the email delivery method,
+// a phone number for the sms delivery method and can only be empty for print and direct_topup.
+func (b VendExecuteBody) Validate() error {
+
+	if strings.TrimSpace(b.TransactionId) == "" {
+		return ValidationError("transaction id is required")
+	}
+	if strings.TrimSpace(b.CustomerAccountNumber) == "" {
+		return ValidationError("customer account number is required")
+	}
+	if b.Amount <= 0 {
+		return ValidationError("amount must be greater than zero")
+	}
+
+	deliverTo := strings.TrimSpace(b.DeliverTo)
+	switch b.DeliveryMethodId {
+	case "":
+		return ValidationError("delivery method is required")
+	case EmailDeliveryMethodId:
+		if !isEmail(deliverTo) {
+			return ValidationError(fmt.Sprintf("invalid email %q to deliver to", b.DeliverTo))
+		}
+	case SmsDeliveryMethodId:
+		if !isPhoneNumber(deliverTo) {
+			return ValidationError(fmt.Sprintf("invalid phone number %q to deliver to", b.DeliverTo))
+		}
+	case PrintDeliveryMethodId, DirectTopupDeliveryMethodId:
+	default:
+		if deliverTo == "" {
+			return ValidationError(fmt.Sprintf("delivery destination is required for %q delivery method", b.DeliveryMethodId))
+		}
+	}
+	return nil
+}
+
+// Check the body against the vend validate response it was built from,
+// in addition to Validate the delivery method must be offered and the transaction details must match.
+func (b VendExecuteBody) ValidateAgainst(resp *VendValidateResp) error {
+
+	err := b.Validate()
+	if err != nil {
+		return err
+	}
+	if b.TransactionId != resp.Data.TransactionId {
+		return ValidationError("transaction id does not match the vend validate response")
+	}
+	if b.VerticalId != resp.Data.VerticalId || !sameAccountNumber(b.VerticalId, b.CustomerAccountNumber, resp.Data.CustomerAccountNumber) {
+		return ValidationError("vertical or customer account number differs from the vend validate response")
+	}
+	if len(resp.Data.DeliveryMethods) == 0 {
+		return nil
+	}
+	for _, m := range resp.Data.DeliveryMethods {
+		if m.Id == b.DeliveryMethodId {
+			return nil
+		}
+	}
+	return ValidationError(fmt.Sprintf("delivery method %q is not offered for this product", b.DeliveryMethodId))
+}
+
+// Whether the account number is the validated one as is or once normalized by the validator registered for the vertical.
+func sameAccountNumber(verticalId, accountNo, validated string) bool {
+
+	if accountNo == validated {
+		return true
+	}
+	normalized, err := ValidateAccountNumber(verticalId, accountNo)
+	return err == nil && normalized == validated
+}
+
+func isEmail(v string) bool {
+
+	addr, err := mail.ParseAddress(v)
+	return err == nil && addr.Address == v
+}
+
+// Accepts international or local phone numbers, separators like spaces and dashes are ignored.
+func isPhoneNumber(v string) bool {
+
+	v = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(v)
+	v = strings.TrimPrefix(v, "+")
+	return len(v) >= 9 && len(v) <= 15 && isDigits(v)
+}
@@ -0,0 +1,165 @@
+package efashevdsapigo
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Checks a customer account number of a vertical and returns it normalized.
+type AccountNumberValidator func(accountNo string) (string, error)
+
+var accountNumberValidators = struct {
+	sync.RWMutex
+	m map[string]AccountNumberValidator
+}{
+	m: map[string]AccountNumberValidator{
+		AirtimeVerticalId:     validateMsisdn,
+		ElectricityVerticalId: digitsValidator("meter number", 11, 13),
+		PayTvVerticalId:       digitsValidator("decoder number", 8, 14),
+		WaterVerticalId:       digitsValidator("water customer number", 4, 12),
+		TaxVerticalId:         validateTaxReference,
+	},
+}
+
+// Register the customer account number validator of a vertical, it replaces any validator previously registered for it.
+func RegisterAccountNumberValidator(verticalId string, validator AccountNumberValidator) {
+
+	accountNumberValidators.Lock()
+	defer accountNumberValidators.Unlock()
+	accountNumberValidators.m[verticalId] = validator
+}
+
+// Check the customer account number with the validator registered for the vertical.
+// Account numbers of verticals without a validator are only required to be non-empty.
+func ValidateAccountNumber(verticalId, accountNo string) (string, error) {
+
+	accountNo = strings.TrimSpace(accountNo)
+	if verticalId == "" {
+		return "", ValidationError("vertical id is required")
+	}
+	if accountNo == "" {
+		return "", ValidationError("customer account number is required")
+	}
+
+	accountNumberValidators.RLock()
+	validator, ok := accountNumberValidators.m[verticalId]
+	accountNumberValidators.RUnlock()
+	if !ok {
+		return accountNo, nil
+	}
+	return validator(accountNo)
+}
+
+// Check the vertical and customer account number of the body.
+func (b VendValidateBody) Validate() error {
+
+	_, err := b.Normalized()
+	return err
+}
+
+// The body with its customer account number checked and normalized by the validator registered for the vertical.
+func (b VendValidateBody) Normalized() (VendValidateBody, error) {
+
+	accountNo, err := ValidateAccountNumber(b.VerticalId, b.CustomerAccountNumber)
+	if err != nil {
+		return VendValidateBody{}, err
+	}
+	b.CustomerAccountNumber = accountNo
+	return b, nil
+}
+
+// Check the vertical and customer account number are set, formats are left to the API.
+func (b VendValidateBody) validateRequired() error {
+
+	if strings.TrimSpace(b.VerticalId) == "" {
+		return ValidationError("vertical id is required")
+	}
+	if strings.TrimSpace(b.CustomerAccountNumber) == "" {
+		return ValidationError("customer account number is required")
+	}
+	return nil
+}
+
+func newVendValidateBody(verticalId, accountNo string) (VendValidateBody, error) {
+
+	accountNo, err := ValidateAccountNumber(verticalId, accountNo)
+	if err != nil {
+		return VendValidateBody{}, err
+	}
+	return VendValidateBody{SharedVendInfo{VerticalId: verticalId, CustomerAccountNumber: accountNo}}, nil
+}
+
+// Airtime topup of a Rwandan mobile number, local (07XXXXXXXX) and international (2507XXXXXXXX) forms are accepted.
+func NewAirtimeValidateBody(msisdn string) (VendValidateBody, error) {
+	return newVendValidateBody(AirtimeVerticalId, msisdn)
+}
+
+// Prepaid electricity of a meter number.
+func NewElectricityValidateBody(meterNo string) (VendValidateBody, error) {
+	return newVendValidateBody(ElectricityVerticalId, meterNo)
+}
+
+// Pay TV subscription of a decoder or smart card number.
+func NewPayTvValidateBody(decoderNo string) (VendValidateBody, error) {
+	return newVendValidateBody(PayTvVerticalId, decoderNo)
+}
+
+// Water bill payment of a customer number.
+// The bill details are returned in the validate response, check VendValidateResp.WaterInfo.
+func NewWaterValidateBody(customerNo string) (VendValidateBody, error) {
+	return newVendValidateBody(WaterVerticalId, customerNo)
+}
+
+// Tax payment of a declaration, the reference is the document or payment reference issued by the revenue authority.
+// The tax details are returned in the validate response, check VendValidateResp.TaxInfo.
+func NewTaxValidateBody(reference string) (VendValidateBody, error) {
+	return newVendValidateBody(TaxVerticalId, reference)
+}
+
+func validateMsisdn(v string) (string, error) {
+
+	n := strings.NewReplacer(" ", "", "-", "").Replace(v)
+	n = strings.TrimPrefix(n, "+")
+	if len(n) == 12 && strings.HasPrefix(n, "250") {
+		n = "0" + n[3:]
+	}
+	if len(n) != 10 || !strings.HasPrefix(n, "07") || !isDigits(n) {
+		return "", ValidationError(fmt.Sprintf("invalid mobile number %q", v))
+	}
+	return n, nil
+}
+
+func validateTaxReference(v string) (string, error) {
+
+	if len(v) < 6 || len(v) > 30 {
+		return "", ValidationError(fmt.Sprintf("invalid tax reference %q", v))
+	}
+	for _, r := range v {
+		if !(r >= '0' && r <= '9' || r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r == '-' || r == '/') {
+			return "", ValidationError(fmt.Sprintf("invalid tax reference %q", v))
+		}
+	}
+	return v, nil
+}
+
+func digitsValidator(name string, minLen, maxLen int) AccountNumberValidator {
+
+	return func(v string) (string, error) {
+		n := strings.ReplaceAll(v, " ", "")
+		if len(n) < minLen || len(n) > maxLen || !isDigits(n) {
+			return "", ValidationError(fmt.Sprintf("invalid %s %q", name, v))
+		}
+		return n, nil
+	}
+}
+
+func isDigits(v string) bool {
+
+	for _, r := range v {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return v != ""
+}